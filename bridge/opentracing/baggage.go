@@ -0,0 +1,203 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opentracing
+
+import (
+	"net/http"
+	"strings"
+
+	ot "github.com/opentracing/opentracing-go"
+
+	"go.opentelemetry.io/otel/internal/baggage"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagators"
+)
+
+// BaggageProperty is a property of a baggage item, as defined by the
+// W3C Baggage specification. A property without a value has an empty
+// Value.
+type BaggageProperty struct {
+	Key   string
+	Value string
+}
+
+// BaggageEntry is the value of a baggage item together with its
+// properties. OpenTracing only knows the plain value, which is what
+// the ForeachBaggageItem and BaggageItem methods return.
+type BaggageEntry struct {
+	Value      string
+	Properties []BaggageProperty
+}
+
+// SetBaggageItemWithProperties sets a baggage item with properties on
+// the passed span. The BridgeTracer hands the properties to the
+// Baggage propagator with propagators.ContextWithBaggageMembers, which
+// injects them after the value. It returns false if the span was not
+// created by a BridgeTracer or the item did not fit in the maximum
+// baggage size.
+func SetBaggageItemWithProperties(span ot.Span, key string, entry BaggageEntry) bool {
+	bSpan, ok := span.(*bridgeSpan)
+	if !ok {
+		return false
+	}
+	value := bSpan.tracer.config.sanitizeBaggageValue(entry.Value)
+	if !bSpan.setBaggageItemOnly(key, value) {
+		return false
+	}
+	bSpan.ctx.setBaggageProperties(key, entry.Properties)
+	bSpan.updateOTelContext(key, value)
+	return true
+}
+
+// BaggageItemEntry returns the baggage item with the passed key of the
+// passed span context, including its properties. The returned boolean
+// is false if there is no such item or the span context was not
+// created by a BridgeTracer.
+func BaggageItemEntry(sc ot.SpanContext, key string) (BaggageEntry, bool) {
+	bridgeSC, ok := sc.(*bridgeSpanContext)
+	if !ok {
+		return BaggageEntry{}, false
+	}
+	crk := label.Key(http.CanonicalHeaderKey(key))
+	if _, ok := bridgeSC.baggageItems.Value(crk); !ok {
+		return BaggageEntry{}, false
+	}
+	return bridgeSC.baggageEntry(crk), true
+}
+
+func (c *bridgeSpanContext) baggageEntry(crk label.Key) BaggageEntry {
+	v, _ := c.baggageItems.Value(crk)
+	return BaggageEntry{
+		Value:      v.Emit(),
+		Properties: append([]BaggageProperty(nil), c.baggageProperties[crk]...),
+	}
+}
+
+// setBaggageEntry stores the baggage item with its properties.
+func (c *bridgeSpanContext) setBaggageEntry(restrictedKey string, entry BaggageEntry) ([]label.Key, bool) {
+	dropped, ok := c.setBaggageItem(restrictedKey, entry.Value)
+	if ok {
+		c.setBaggageProperties(restrictedKey, entry.Properties)
+	}
+	return dropped, ok
+}
+
+// setExtractedBaggageEntry stores the baggage item with its properties
+// like setBaggageEntry, but keeps the exact casing of key, which the
+// ForeachBaggageItem method returns and Inject injects. Lookups are
+// still case-insensitive. It returns false if the item did not fit in
+// the maximum baggage size.
+func (c *bridgeSpanContext) setExtractedBaggageEntry(key string, entry BaggageEntry) bool {
+	if _, ok := c.setBaggageEntry(key, entry); !ok {
+		return false
+	}
+	crk := label.Key(http.CanonicalHeaderKey(key))
+	if string(crk) == key {
+		return true
+	}
+	if c.extractedKeys == nil {
+		c.extractedKeys = make(map[label.Key]string)
+	}
+	c.extractedKeys[crk] = key
+	return true
+}
+
+// baggageKey returns the key of the baggage item stored under the
+// canonical key crk, with its extracted casing if it was extracted.
+func (c *bridgeSpanContext) baggageKey(crk label.Key) string {
+	if key, ok := c.extractedKeys[crk]; ok {
+		return key
+	}
+	return string(crk)
+}
+
+func (c *bridgeSpanContext) setBaggageProperties(restrictedKey string, props []BaggageProperty) {
+	if len(props) == 0 {
+		return
+	}
+	if c.baggageProperties == nil {
+		c.baggageProperties = make(map[label.Key][]BaggageProperty)
+	}
+	crk := label.Key(http.CanonicalHeaderKey(restrictedKey))
+	c.baggageProperties[crk] = append([]BaggageProperty(nil), props...)
+}
+
+// injectedBaggage returns the baggage items, the extracted ones under
+// their exact keys.
+func (c *bridgeSpanContext) injectedBaggage() baggage.Map {
+	if len(c.extractedKeys) == 0 {
+		return c.baggageItems
+	}
+	kvs := make([]label.KeyValue, 0, len(c.baggageOrder))
+	for _, k := range c.baggageOrder {
+		v, _ := c.baggageItems.Value(k)
+		kvs = append(kvs, label.String(c.baggageKey(k), v.Emit()))
+	}
+	return baggage.NewMap(baggage.MapUpdate{MultiKV: kvs})
+}
+
+// baggageMembers returns the baggage items with properties, keyed like
+// in injectedBaggage, for the Baggage propagator to inject the
+// properties.
+func (c *bridgeSpanContext) baggageMembers() map[string]propagators.BaggageMember {
+	if len(c.baggageProperties) == 0 {
+		return nil
+	}
+	members := make(map[string]propagators.BaggageMember, len(c.baggageProperties))
+	for k, props := range c.baggageProperties {
+		v, _ := c.baggageItems.Value(k)
+		member := propagators.BaggageMember{Value: v.Emit()}
+		for _, p := range props {
+			member.Properties = append(member.Properties, propagators.BaggageProperty(p))
+		}
+		members[c.baggageKey(k)] = member
+	}
+	return members
+}
+
+// extractedBaggageEntry returns the baggage item kv extracted by the
+// propagator with the properties the Baggage propagator extracted
+// along with it. The value of an item without properties is kept
+// whole, semicolons included.
+func extractedBaggageEntry(kv label.KeyValue, members map[string]propagators.BaggageMember) BaggageEntry {
+	value := kv.Value.Emit()
+	m, ok := members[string(kv.Key)]
+	if !ok || !strings.HasPrefix(value, m.Value+";") {
+		return BaggageEntry{Value: value}
+	}
+	entry := BaggageEntry{Value: m.Value}
+	for _, p := range m.Properties {
+		entry.Properties = append(entry.Properties, BaggageProperty(p))
+	}
+	return entry
+}
+
+// sanitizeBaggageValue is the default baggage value sanitizer. It
+// removes the ASCII control characters of value.
+func sanitizeBaggageValue(value string) string {
+	if strings.IndexFunc(value, isASCIIControl) < 0 {
+		return value
+	}
+	return strings.Map(func(r rune) rune {
+		if isASCIIControl(r) {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+func isASCIIControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opentracing
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+
+	ot "github.com/opentracing/opentracing-go"
+)
+
+const (
+	// binaryFrameHeaderLen is the length of the big-endian frame length
+	// preceding every frame.
+	binaryFrameHeaderLen = 4
+	// maxBinaryFrameLen bounds the length of a frame read by Extract,
+	// so a corrupted stream does not make it allocate a huge buffer.
+	maxBinaryFrameLen = 1 << 20
+)
+
+// writeBinaryFrame writes the fields of header to w as a single frame
+// of the Binary format.
+//
+// A frame is the length of its payload, as a big-endian uint32,
+// followed by the payload. The payload holds, for every field in
+// sorted key order, the length of the key, the key, the length of the
+// value and the value, the lengths being encoded as unsigned varints.
+// The framing lets several span contexts be written to the same stream
+// one after the other.
+func writeBinaryFrame(w io.Writer, header http.Header) error {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	frame := make([]byte, binaryFrameHeaderLen)
+	for _, k := range keys {
+		for _, v := range header[k] {
+			frame = appendFrameString(frame, k)
+			frame = appendFrameString(frame, v)
+		}
+	}
+	binary.BigEndian.PutUint32(frame, uint32(len(frame)-binaryFrameHeaderLen))
+	_, err := w.Write(frame)
+	return err
+}
+
+func appendFrameString(data []byte, s string) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(len(s)))
+	data = append(data, buf[:n]...)
+	return append(data, s...)
+}
+
+// readBinaryFrame reads exactly one frame written by writeBinaryFrame
+// from r and returns its fields, leaving r positioned at the next
+// frame. It returns opentracing.ErrSpanContextNotFound if r is at the
+// end of the stream and opentracing.ErrSpanContextCorrupted if the
+// frame is truncated or malformed.
+func readBinaryFrame(r io.Reader) (http.Header, error) {
+	var lenBuf [binaryFrameHeaderLen]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ot.ErrSpanContextNotFound
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ot.ErrSpanContextCorrupted
+		}
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(lenBuf[:])
+	if n > maxBinaryFrameLen {
+		return nil, ot.ErrSpanContextCorrupted
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ot.ErrSpanContextCorrupted
+		}
+		return nil, err
+	}
+
+	header := http.Header{}
+	for len(payload) > 0 {
+		var k, v string
+		var ok bool
+		if k, payload, ok = readFrameString(payload); !ok {
+			return nil, ot.ErrSpanContextCorrupted
+		}
+		if v, payload, ok = readFrameString(payload); !ok {
+			return nil, ot.ErrSpanContextCorrupted
+		}
+		header[k] = append(header[k], v)
+	}
+	return header, nil
+}
+
+func readFrameString(data []byte) (string, []byte, bool) {
+	n, l := binary.Uvarint(data)
+	if l <= 0 || uint64(len(data)-l) < n {
+		return "", nil, false
+	}
+	data = data[l:]
+	return string(data[:n]), data[n:], true
+}
@@ -17,9 +17,12 @@ package opentracing
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	ot "github.com/opentracing/opentracing-go"
 	otext "github.com/opentracing/opentracing-go/ext"
@@ -32,40 +35,146 @@ import (
 	"go.opentelemetry.io/otel/internal/trace/noop"
 	otelparent "go.opentelemetry.io/otel/internal/trace/parent"
 	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagators"
+	"go.opentelemetry.io/otel/semconv"
 
 	"go.opentelemetry.io/otel/bridge/opentracing/migration"
 )
 
 type bridgeSpanContext struct {
 	baggageItems    baggage.Map
+	baggageOrder    []label.Key
+	baggageLimits   config
 	otelSpanContext otel.SpanContext
+	// baggageProperties are the properties of the baggage items
+	// that have some.
+	baggageProperties map[label.Key][]BaggageProperty
+	// extractedKeys are the exact keys of the extracted baggage items
+	// whose casing differs from the canonical one they are stored
+	// under.
+	extractedKeys map[label.Key]string
+	// extractedLinks are the additional span contexts found by
+	// Extract next to the one used as otelSpanContext.
+	extractedLinks []otel.Link
+	// tracestate is the W3C tracestate extracted next to the span
+	// context, which Inject injects again for the descendants of the
+	// extracted span context.
+	tracestate string
+	// remote is true for the span contexts that come from another
+	// process, like the ones returned by Extract, NewRemoteSpanContext
+	// and ParseSpanContextJSON.
+	remote bool
 }
 
 var _ ot.SpanContext = &bridgeSpanContext{}
 
-func newBridgeSpanContext(otelSpanContext otel.SpanContext, parentOtSpanContext ot.SpanContext) *bridgeSpanContext {
+func newBridgeSpanContext(otelSpanContext otel.SpanContext, parentOtSpanContext ot.SpanContext, limits config) *bridgeSpanContext {
 	bCtx := &bridgeSpanContext{
 		baggageItems:    baggage.NewEmptyMap(),
+		baggageLimits:   limits,
 		otelSpanContext: otelSpanContext,
 	}
-	if parentOtSpanContext != nil {
+	if parentBridgeSC, ok := parentOtSpanContext.(*bridgeSpanContext); ok {
+		bCtx.tracestate = parentBridgeSC.tracestate
+		for _, k := range parentBridgeSC.baggageOrder {
+			bCtx.setExtractedBaggageEntry(parentBridgeSC.baggageKey(k), parentBridgeSC.baggageEntry(k))
+		}
+	} else if parentOtSpanContext != nil {
 		parentOtSpanContext.ForeachBaggageItem(func(key, value string) bool {
-			bCtx.setBaggageItem(key, value)
+			bCtx.setBaggageItem(key, limits.sanitizeBaggageValue(value))
 			return true
 		})
 	}
 	return bCtx
 }
 
+// ForeachBaggageItem calls handler for the baggage items in the order
+// of their keys, so the iteration order is stable whatever the order
+// the items were set or extracted in.
 func (c *bridgeSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {
-	c.baggageItems.Foreach(func(kv label.KeyValue) bool {
-		return handler(string(kv.Key), kv.Value.Emit())
-	})
+	keys := make([]label.Key, len(c.baggageOrder))
+	copy(keys, c.baggageOrder)
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	for _, k := range keys {
+		v, _ := c.baggageItems.Value(k)
+		if !handler(c.baggageKey(k), v.Emit()) {
+			return
+		}
+	}
 }
 
-func (c *bridgeSpanContext) setBaggageItem(restrictedKey, value string) {
-	crk := http.CanonicalHeaderKey(restrictedKey)
-	c.baggageItems = c.baggageItems.Apply(baggage.MapUpdate{SingleKV: label.String(crk, value)})
+// setBaggageItem stores the baggage item, enforcing the configured
+// maximum baggage size and number of baggage items. It returns the
+// keys of the items that were dropped to make room for the new one and
+// whether the new item was stored at all.
+func (c *bridgeSpanContext) setBaggageItem(restrictedKey, value string) ([]label.Key, bool) {
+	crk := label.Key(http.CanonicalHeaderKey(restrictedKey))
+	update := baggage.MapUpdate{SingleKV: crk.String(value)}
+	if limits := c.baggageLimits; limits.maxBaggageSize > 0 || limits.maxBaggageItems > 0 {
+		size := c.baggageSize() + baggageItemSize(string(crk), value)
+		items := len(c.baggageOrder) + 1
+		if old, ok := c.baggageItems.Value(crk); ok {
+			size -= baggageItemSize(string(crk), old.Emit())
+			items--
+		}
+		if limits.baggageLimitsExceeded(size, items) {
+			if limits.baggageTruncationPolicy != BaggageTruncationDropOldest {
+				return nil, false
+			}
+			for _, k := range c.baggageOrder {
+				if !limits.baggageLimitsExceeded(size, items) {
+					break
+				}
+				if k == crk {
+					continue
+				}
+				v, _ := c.baggageItems.Value(k)
+				size -= baggageItemSize(string(k), v.Emit())
+				items--
+				update.DropMultiK = append(update.DropMultiK, k)
+			}
+			if limits.baggageLimitsExceeded(size, items) {
+				return nil, false
+			}
+		}
+	}
+	c.baggageItems = c.baggageItems.Apply(update)
+	c.reorderBaggage(crk, update.DropMultiK)
+	delete(c.baggageProperties, crk)
+	delete(c.extractedKeys, crk)
+	for _, k := range update.DropMultiK {
+		delete(c.baggageProperties, k)
+		delete(c.extractedKeys, k)
+	}
+	return update.DropMultiK, true
+}
+
+// reorderBaggage moves the updated key to the end of the insertion
+// order and forgets the dropped keys.
+func (c *bridgeSpanContext) reorderBaggage(updated label.Key, dropped []label.Key) {
+	order := make([]label.Key, 0, len(c.baggageOrder)+1)
+	for _, k := range c.baggageOrder {
+		if k == updated || containsKey(dropped, k) {
+			continue
+		}
+		order = append(order, k)
+	}
+	c.baggageOrder = append(order, updated)
+}
+
+// sortedBaggage returns the items of m in the order of their keys. The
+// bridge stores the items of a baggage.Map, whose iteration order is
+// random, in this order, so the oldest items dropped by
+// BaggageTruncationDropOldest and the items over the incoming size
+// limit are the same for the same baggage.
+func sortedBaggage(m baggage.Map) []label.KeyValue {
+	kvs := make([]label.KeyValue, 0, m.Len())
+	m.Foreach(func(kv label.KeyValue) bool {
+		kvs = append(kvs, kv)
+		return true
+	})
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return kvs
 }
 
 func (c *bridgeSpanContext) baggageItem(restrictedKey string) string {
@@ -74,23 +183,150 @@ func (c *bridgeSpanContext) baggageItem(restrictedKey string) string {
 	return val.Emit()
 }
 
+func (c *bridgeSpanContext) baggageSize() int {
+	return BaggageSize(c)
+}
+
+// BaggageSize returns the total size in bytes of the baggage items in
+// the passed span context, as accounted for by the limit set with
+// WithMaxBaggageSize. It can be used to check whether a new item
+// would fit before calling SetBaggageItem.
+func BaggageSize(sc ot.SpanContext) int {
+	size := 0
+	sc.ForeachBaggageItem(func(k, v string) bool {
+		size += baggageItemSize(k, v)
+		return true
+	})
+	return size
+}
+
+func baggageItemSize(key, value string) int {
+	return len(key) + len(value)
+}
+
+// ExtractedLinks returns the links to the additional span contexts
+// that were found by BridgeTracer.Extract next to the returned span
+// context. Spans started with a reference to the passed span context
+// are linked to them too. Nil is returned if there are no such links
+// or the span context was not created by a BridgeTracer.
+func ExtractedLinks(sc ot.SpanContext) []otel.Link {
+	bridgeSC, ok := sc.(*bridgeSpanContext)
+	if !ok || len(bridgeSC.extractedLinks) == 0 {
+		return nil
+	}
+	return append([]otel.Link{}, bridgeSC.extractedLinks...)
+}
+
+func containsKey(keys []label.Key, key label.Key) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusCodeTagKey is the key of a tag that sets the status of the
+// OpenTelemetry span. The supported values are "unset", "ok" and
+// "error", compared case-insensitively, and the codes.Code values.
+// OpenTracing has no notion of a successful span, so this tag is the
+// only way to mark one. Unlike the boolean error tag, it can also reset
+// the status to Unset.
+const StatusCodeTagKey = "otel.status_code"
+
+// StatusDescriptionTagKey is the key of a tag that sets the description
+// of the Error status of the OpenTelemetry span, whether the status is
+// set by the StatusCodeTagKey tag or by the error tag. It takes
+// precedence over the error.message and message tags. Like
+// StatusCodeTagKey, it is not recorded as an attribute.
+const StatusDescriptionTagKey = "otel.status_description"
+
+// tagsEventName is the name of the span event holding the tags
+// configured with WithHighCardinalityTagsAsEvents.
+const tagsEventName = "ot-tags"
+
+// extractedLinkKey is the key of the link attribute describing where
+// an extracted link came from.
+const extractedLinkKey = label.Key("ot-extracted")
+
+// linkEventName is the name of the span event recorded by
+// AddLinkEvent.
+const linkEventName = "ot-link"
+
+// Keys of the attributes of the span event recorded by AddLinkEvent.
+const (
+	linkEventTraceIDKey = label.Key("link.trace_id")
+	linkEventSpanIDKey  = label.Key("link.span_id")
+	linkEventReasonKey  = label.Key("link.reason")
+)
+
+// baggageEventName is the name of the span event recorded for every
+// SetBaggageItem call when WithBaggageDebugEvents is used.
+const baggageEventName = "ot-baggage"
+
+// Keys of the attributes of the span event recorded for a
+// SetBaggageItem call.
+const (
+	baggageEventKeyKey      = label.Key("baggage.key")
+	baggageEventValueKey    = label.Key("baggage.value")
+	baggageEventAcceptedKey = label.Key("baggage.accepted")
+)
+
+// Keys of the OpenTracing tags describing the error of a span, used as
+// the description of the Error status set by the error tag.
+const (
+	errorMessageTagKey = "error.message"
+	messageTagKey      = "message"
+)
+
+// renameEventName is the name of the span event recorded for every
+// SetOperationName call when WithNameChangeEvents is used.
+const renameEventName = "span.rename"
+
+// Keys of the attributes of the span event recorded for a
+// SetOperationName call.
+const (
+	renameEventOldKey = label.Key("old")
+	renameEventNewKey = label.Key("new")
+)
+
 type bridgeSpan struct {
-	otelSpan          otel.Span
-	ctx               *bridgeSpanContext
-	tracer            *BridgeTracer
-	skipDeferHook     bool
+	otelSpan      otel.Span
+	ctx           *bridgeSpanContext
+	tracer        *BridgeTracer
+	skipDeferHook bool
+	// baggageLock guards extraBaggageItems.
+	baggageLock       sync.Mutex
 	extraBaggageItems map[string]string
+	statusCode        codes.Code
+	kind              otel.SpanKind
+	// name is the operation name of the span, if known.
+	name string
+	// statusDescription, errorMessage and message are the values of
+	// the tags describing the error of the span.
+	statusDescription, errorMessage, message string
+	// errorFromTag is true if the Error status was set by a tag, so
+	// the tags describing the error update its description.
+	errorFromTag bool
+	// events and droppedEvents count the events added to the span and
+	// the ones dropped because of the limit set with WithMaxEvents.
+	// They are accessed atomically.
+	events, droppedEvents int64
+	// remoteParent is true if the parent of the span is a span context
+	// returned by Extract.
+	remoteParent bool
 }
 
 var _ ot.Span = &bridgeSpan{}
 
-func newBridgeSpan(otelSpan otel.Span, bridgeSC *bridgeSpanContext, tracer *BridgeTracer) *bridgeSpan {
+func newBridgeSpan(otelSpan otel.Span, bridgeSC *bridgeSpanContext, tracer *BridgeTracer, kind otel.SpanKind) *bridgeSpan {
 	return &bridgeSpan{
 		otelSpan:          otelSpan,
 		ctx:               bridgeSC,
 		tracer:            tracer,
 		skipDeferHook:     false,
 		extraBaggageItems: nil,
+		kind:              kind,
 	}
 }
 
@@ -114,8 +350,11 @@ func (s *bridgeSpan) FinishWithOptions(opts ot.FinishOptions) {
 }
 
 func (s *bridgeSpan) logRecord(record ot.LogRecord) {
-	s.otelSpan.AddEvent(
-		"",
+	if !s.otelSpan.IsRecording() {
+		return
+	}
+	s.addEvent(
+		otLogFieldsToEventName(record.Fields),
 		otel.WithTimestamp(record.Timestamp),
 		otel.WithAttributes(otLogFieldsToOTelLabels(record.Fields)...),
 	)
@@ -126,31 +365,285 @@ func (s *bridgeSpan) Context() ot.SpanContext {
 }
 
 func (s *bridgeSpan) SetOperationName(operationName string) ot.Span {
+	if s.tracer.config.nameChangeEvents {
+		s.addEvent(renameEventName, otel.WithAttributes(
+			renameEventOldKey.String(s.name),
+			renameEventNewKey.String(operationName),
+		))
+	}
+	s.name = operationName
 	s.otelSpan.SetName(operationName)
 	return s
 }
 
+// setTagAttributes records the attributes of the tag, as a single
+// event if the tag was configured with WithHighCardinalityTagsAsEvents.
+func (s *bridgeSpan) setTagAttributes(key string, value interface{}) {
+	attrs := s.tracer.config.otTagToOTelLabels(key, value)
+	if s.tracer.config.isEventTag(key) {
+		s.addEvent(tagsEventName, otel.WithAttributes(attrs...))
+		return
+	}
+	s.otelSpan.SetAttributes(attrs...)
+}
+
 func (s *bridgeSpan) SetTag(key string, value interface{}) ot.Span {
 	switch key {
 	case string(otext.SpanKind):
 		// TODO: Should we ignore it?
 	case string(otext.Error):
 		if b, ok := value.(bool); ok && b {
-			s.otelSpan.SetStatus(codes.Error, "")
+			s.setErrorFromTag()
+		}
+	case StatusCodeTagKey:
+		s.setStatusFromTag(value)
+	case StatusDescriptionTagKey:
+		s.setErrorDescriptionTag(key, value)
+		if s.errorFromTag {
+			s.otelSpan.SetStatus(codes.Error, s.errorDescription())
+		}
+	case string(otext.HTTPStatusCode):
+		s.setTagAttributes(key, value)
+		if s.tracer.config.httpStatusToSpanStatus {
+			s.setStatusFromHTTPStatus(value)
 		}
 	default:
-		s.otelSpan.SetAttributes(otTagToOTelLabel(key, value))
+		s.setTagAttributes(key, value)
+		if s.setErrorDescriptionTag(key, value) && s.errorFromTag {
+			s.otelSpan.SetStatus(codes.Error, s.errorDescription())
+		}
 	}
 	return s
 }
 
+// setErrorDescriptionTag stores the value of a tag describing the
+// error of the span. It returns false for other tags.
+func (s *bridgeSpan) setErrorDescriptionTag(key string, value interface{}) bool {
+	switch key {
+	case StatusDescriptionTagKey:
+		s.statusDescription = fmt.Sprint(value)
+	case errorMessageTagKey:
+		s.errorMessage = fmt.Sprint(value)
+	case messageTagKey:
+		s.message = fmt.Sprint(value)
+	default:
+		return false
+	}
+	return true
+}
+
+// errorDescription returns the description of an Error status set by
+// a tag. The StatusDescriptionTagKey tag takes precedence over the
+// error.message tag, which takes precedence over the message tag.
+func (s *bridgeSpan) errorDescription() string {
+	if s.statusDescription != "" {
+		return s.statusDescription
+	}
+	if s.errorMessage != "" {
+		return s.errorMessage
+	}
+	return s.message
+}
+
+// setErrorFromTag sets an Error status described by the tags
+// describing the error, which keep updating the description when set
+// later.
+func (s *bridgeSpan) setErrorFromTag() {
+	s.setStatus(codes.Error, s.errorDescription(), false)
+	s.errorFromTag = s.statusCode == codes.Error
+}
+
+// setStatus sets the status of the OTel span. Following the status
+// precedence rules, an Ok status does not override an Error status
+// unless force is true.
+func (s *bridgeSpan) setStatus(code codes.Code, msg string, force bool) {
+	if code == codes.Ok && s.statusCode == codes.Error && !force {
+		return
+	}
+	s.errorFromTag = false
+	s.statusCode = code
+	s.otelSpan.SetStatus(code, msg)
+}
+
+// setStatusFromTag sets the status named by the value of the
+// StatusCodeTagKey tag. An Unset status always replaces the current
+// one, an Ok status never replaces an Error status.
+func (s *bridgeSpan) setStatusFromTag(value interface{}) {
+	var code codes.Code
+	switch v := value.(type) {
+	case codes.Code:
+		code = v
+	case string:
+		switch strings.ToLower(v) {
+		case "unset":
+			code = codes.Unset
+		case "ok":
+			code = codes.Ok
+		case "error":
+			code = codes.Error
+		default:
+			return
+		}
+	default:
+		return
+	}
+	switch code {
+	case codes.Unset:
+		s.setStatus(codes.Unset, "", true)
+	case codes.Ok:
+		s.setStatus(codes.Ok, "", false)
+	case codes.Error:
+		s.setErrorFromTag()
+	}
+}
+
+// setStatusFromHTTPStatus sets an Error status if the passed HTTP
+// status code denotes a failure of the span. Client spans fail on 4xx
+// and 5xx codes, other spans only on 5xx codes, because a 4xx code
+// returned by a server is a failure of its caller.
+func (s *bridgeSpan) setStatusFromHTTPStatus(value interface{}) {
+	code, ok := intFromTag(value)
+	if !ok {
+		return
+	}
+	threshold := http.StatusInternalServerError
+	if s.kind == otel.SpanKindClient {
+		threshold = http.StatusBadRequest
+	}
+	if code >= threshold {
+		s.setStatus(codes.Error, http.StatusText(code), false)
+	}
+}
+
+// intFromTag returns the value of a tag set to an integer of any type.
+func intFromTag(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int8:
+		return int(v), true
+	case int16:
+		return int(v), true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case uint:
+		return int(v), true
+	case uint8:
+		return int(v), true
+	case uint16:
+		return int(v), true
+	case uint32:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+// SetStatusOK sets the status of the OpenTelemetry span behind the
+// passed span to Ok, even if the status was already set to Error. It
+// returns false if the span was not created by a BridgeTracer.
+//
+// Setting the StatusCodeTagKey tag to "ok" does not override an Error
+// status.
+func SetStatusOK(span ot.Span) bool {
+	bSpan, ok := span.(*bridgeSpan)
+	if !ok {
+		return false
+	}
+	bSpan.setStatus(codes.Ok, "", true)
+	return true
+}
+
+// AddLinkEvent records a causal relationship between the passed span
+// and the referenced span context after the span was started.
+// OpenTracing references can only be passed when starting a span and
+// OpenTelemetry links can only be added at the same time, so the
+// relationship is recorded as a span event instead. The event is named
+// "ot-link" and has the following attributes:
+//
+//	link.trace_id - the hex encoded trace ID of the referenced context
+//	link.span_id  - the hex encoded span ID of the referenced context
+//	link.reason   - the passed reason, omitted if empty
+//
+// It returns false and records nothing if the span was not created by
+// a BridgeTracer or if the referenced span context is not a valid span
+// context of a BridgeTracer.
+func AddLinkEvent(span ot.Span, ref ot.SpanContext, reason string) bool {
+	bSpan, ok := span.(*bridgeSpan)
+	if !ok {
+		return false
+	}
+	bRef, ok := ref.(*bridgeSpanContext)
+	if !ok || !bRef.otelSpanContext.IsValid() {
+		return false
+	}
+	attrs := []label.KeyValue{
+		linkEventTraceIDKey.String(bRef.otelSpanContext.TraceID.String()),
+		linkEventSpanIDKey.String(bRef.otelSpanContext.SpanID.String()),
+	}
+	if reason != "" {
+		attrs = append(attrs, linkEventReasonKey.String(reason))
+	}
+	bSpan.addEvent(linkEventName, otel.WithAttributes(attrs...))
+	return true
+}
+
+// LogFields records the fields as a span event. The fields are not
+// marshaled at all if the span is not recording, so lazy loggers are
+// not evaluated in vain. The name of the event is the value of the
+// "event" field, or the value of the "message" field if there is no
+// "event" field, or "log" if there is neither. All the fields,
+// including the one used for the name, are recorded as attributes.
 func (s *bridgeSpan) LogFields(fields ...otlog.Field) {
-	s.otelSpan.AddEvent(
-		"",
+	if !s.otelSpan.IsRecording() {
+		return
+	}
+	s.addEvent(
+		otLogFieldsToEventName(fields),
 		otel.WithAttributes(otLogFieldsToOTelLabels(fields)...),
 	)
 }
 
+// addEvent adds an event to the OpenTelemetry span unless the span
+// already has the maximum number of events set with WithMaxEvents.
+func (s *bridgeSpan) addEvent(name string, opts ...otel.EventOption) {
+	max := s.tracer.config.maxEvents
+	if n := atomic.AddInt64(&s.events, 1); max > 0 && n > int64(max) {
+		// Warn once per span, when its first event is dropped.
+		if atomic.AddInt64(&s.droppedEvents, 1) == 1 {
+			s.tracer.warningHandler(fmt.Sprintf("Span reached the maximum of %d events, dropping the next ones\n", max))
+		}
+		return
+	}
+	s.otelSpan.AddEvent(name, opts...)
+}
+
+// HasRemoteParent returns whether the parent of the passed span is a
+// span context of another process, as returned by the Extract method
+// of a BridgeTracer, NewRemoteSpanContext or ParseSpanContextJSON,
+// which makes the span the entry point of the trace in this process. It
+// returns false for a span without parent, for a span whose parent is
+// a local span and for a span that was not created by a BridgeTracer.
+func HasRemoteParent(span ot.Span) bool {
+	bSpan, ok := span.(*bridgeSpan)
+	return ok && bSpan.remoteParent
+}
+
+// DroppedEvents returns the number of events the bridge did not add to
+// the OpenTelemetry span behind the passed span because of the limit
+// set with WithMaxEvents. It returns 0 if the span was not created by a
+// BridgeTracer.
+func DroppedEvents(span ot.Span) int {
+	bSpan, ok := span.(*bridgeSpan)
+	if !ok {
+		return 0
+	}
+	return int(atomic.LoadInt64(&bSpan.droppedEvents))
+}
+
 type bridgeFieldEncoder struct {
 	pairs []label.KeyValue
 }
@@ -158,51 +651,81 @@ type bridgeFieldEncoder struct {
 var _ otlog.Encoder = &bridgeFieldEncoder{}
 
 func (e *bridgeFieldEncoder) EmitString(key, value string) {
-	e.emitCommon(key, value)
+	e.emit(otTagToOTelLabelKey(key).String(value))
 }
 
 func (e *bridgeFieldEncoder) EmitBool(key string, value bool) {
-	e.emitCommon(key, value)
+	e.emit(otTagToOTelLabelKey(key).Bool(value))
 }
 
 func (e *bridgeFieldEncoder) EmitInt(key string, value int) {
-	e.emitCommon(key, value)
+	e.emit(otTagToOTelLabelKey(key).Int(value))
 }
 
 func (e *bridgeFieldEncoder) EmitInt32(key string, value int32) {
-	e.emitCommon(key, value)
+	e.emit(otTagToOTelLabelKey(key).Int32(value))
 }
 
 func (e *bridgeFieldEncoder) EmitInt64(key string, value int64) {
-	e.emitCommon(key, value)
+	e.emit(otTagToOTelLabelKey(key).Int64(value))
 }
 
 func (e *bridgeFieldEncoder) EmitUint32(key string, value uint32) {
-	e.emitCommon(key, value)
+	e.emit(otTagToOTelLabelKey(key).Uint32(value))
 }
 
 func (e *bridgeFieldEncoder) EmitUint64(key string, value uint64) {
-	e.emitCommon(key, value)
+	e.emit(otTagToOTelLabelKey(key).Uint64(value))
 }
 
 func (e *bridgeFieldEncoder) EmitFloat32(key string, value float32) {
-	e.emitCommon(key, value)
+	e.emit(otTagToOTelLabelKey(key).Float32(value))
 }
 
 func (e *bridgeFieldEncoder) EmitFloat64(key string, value float64) {
-	e.emitCommon(key, value)
+	e.emit(otTagToOTelLabelKey(key).Float64(value))
 }
 
+// EmitObject converts the value like a tag, so objects of the types
+// with a label counterpart keep their type.
 func (e *bridgeFieldEncoder) EmitObject(key string, value interface{}) {
-	e.emitCommon(key, value)
+	e.emit(otTagToOTelLabel(key, value))
 }
 
 func (e *bridgeFieldEncoder) EmitLazyLogger(value otlog.LazyLogger) {
 	value(e)
 }
 
-func (e *bridgeFieldEncoder) emitCommon(key string, value interface{}) {
-	e.pairs = append(e.pairs, otTagToOTelLabel(key, value))
+func (e *bridgeFieldEncoder) emit(kv label.KeyValue) {
+	e.pairs = append(e.pairs, kv)
+}
+
+// Keys of the OpenTracing log fields the name of the span event is
+// taken from, in the order of precedence, and the name used when none
+// of them is present.
+const (
+	logEventFieldKey   = "event"
+	logMessageFieldKey = "message"
+	defaultLogName     = "log"
+)
+
+func otLogFieldsToEventName(fields []otlog.Field) string {
+	var message string
+	hasMessage := false
+	for _, field := range fields {
+		switch field.Key() {
+		case logEventFieldKey:
+			return fmt.Sprint(field.Value())
+		case logMessageFieldKey:
+			if !hasMessage {
+				message, hasMessage = fmt.Sprint(field.Value()), true
+			}
+		}
+	}
+	if hasMessage {
+		return message
+	}
+	return defaultLogName
 }
 
 func otLogFieldsToOTelLabels(fields []otlog.Field) []label.KeyValue {
@@ -222,22 +745,80 @@ func (s *bridgeSpan) LogKV(alternatingKeyValues ...interface{}) {
 }
 
 func (s *bridgeSpan) SetBaggageItem(restrictedKey, value string) ot.Span {
-	s.updateOTelContext(restrictedKey, value)
-	s.setBaggageItemOnly(restrictedKey, value)
+	value = s.tracer.config.sanitizeBaggageValue(value)
+	ok := s.setBaggageItemOnly(restrictedKey, value)
+	if ok {
+		s.updateOTelContext(restrictedKey, value)
+	}
+	if s.tracer.config.baggageDebugEvents {
+		s.addBaggageDebugEvent(restrictedKey, value, ok)
+	}
 	return s
 }
 
-func (s *bridgeSpan) setBaggageItemOnly(restrictedKey, value string) {
-	s.ctx.setBaggageItem(restrictedKey, value)
+func (s *bridgeSpan) addBaggageDebugEvent(restrictedKey, value string, accepted bool) {
+	redact := s.tracer.config.baggageRedactor
+	if redact == nil {
+		redact = redactBaggageValue
+	}
+	s.addEvent(baggageEventName, otel.WithAttributes(
+		baggageEventKeyKey.String(restrictedKey),
+		baggageEventValueKey.String(redact(restrictedKey, value)),
+		baggageEventAcceptedKey.Bool(accepted),
+	))
+}
+
+func redactBaggageValue(string, string) string {
+	return "[redacted]"
+}
+
+func (s *bridgeSpan) setBaggageItemOnly(restrictedKey, value string) bool {
+	dropped, ok := s.ctx.setBaggageItem(restrictedKey, value)
+	if !ok {
+		s.tracer.warningHandler(fmt.Sprintf("Baggage item %q does not fit in the maximum baggage size, dropping it\n", restrictedKey))
+		return false
+	}
+	if len(dropped) == 0 {
+		return true
+	}
+	s.baggageLock.Lock()
+	defer s.baggageLock.Unlock()
+	for k := range s.extraBaggageItems {
+		if containsKey(dropped, label.Key(http.CanonicalHeaderKey(k))) {
+			delete(s.extraBaggageItems, k)
+		}
+	}
+	return true
 }
 
 func (s *bridgeSpan) updateOTelContext(restrictedKey, value string) {
+	s.baggageLock.Lock()
+	defer s.baggageLock.Unlock()
 	if s.extraBaggageItems == nil {
 		s.extraBaggageItems = make(map[string]string)
 	}
 	s.extraBaggageItems[restrictedKey] = value
 }
 
+// PendingBaggageItems returns a copy of the baggage items set on the
+// passed span with SetBaggageItem that the bridge contributes to the
+// OpenTelemetry baggage of the contexts with the span. It can be called
+// while another goroutine sets baggage items on the span. It returns
+// nil if the span was not created by a BridgeTracer.
+func PendingBaggageItems(span ot.Span) map[string]string {
+	bSpan, ok := span.(*bridgeSpan)
+	if !ok {
+		return nil
+	}
+	bSpan.baggageLock.Lock()
+	defer bSpan.baggageLock.Unlock()
+	items := make(map[string]string, len(bSpan.extraBaggageItems))
+	for k, v := range bSpan.extraBaggageItems {
+		items[k] = v
+	}
+	return items
+}
+
 func (s *bridgeSpan) BaggageItem(restrictedKey string) string {
 	return s.ctx.baggageItem(restrictedKey)
 }
@@ -290,9 +871,26 @@ type BridgeWarningHandler func(msg string)
 type BridgeTracer struct {
 	setTracer bridgeSetTracer
 
+	config config
+
 	warningHandler BridgeWarningHandler
 	warnOnce       sync.Once
+	// emptyNameWarnOnce emits the warning about spans started with an
+	// empty operation name once.
+	emptyNameWarnOnce sync.Once
+	// incomingBaggageWarnOnce emits the warning about extracted
+	// baggage exceeding the maximum incoming size once.
+	incomingBaggageWarnOnce sync.Once
+
+	// propagator holds the propagatorHolder of the propagator set with
+	// SetTextMapPropagator. It is an atomic.Value, so the propagator
+	// can be replaced while spans are injected and extracted.
+	propagator atomic.Value
+}
 
+// propagatorHolder wraps a propagator, so values of the same type are
+// stored in the propagator field of a BridgeTracer, even nil ones.
+type propagatorHolder struct {
 	propagator otel.TextMapPropagator
 }
 
@@ -303,14 +901,17 @@ var _ ot.TracerContextWithSpanExtension = &BridgeTracer{}
 // the calls to the OpenTelemetry Noop tracer, so it should be
 // overridden with the SetOpenTelemetryTracer function. The warnings
 // handler does nothing by default, so to override it use the
-// SetWarningHandler function.
-func NewBridgeTracer() *BridgeTracer {
+// SetWarningHandler function. The passed options configure the
+// behavior of the tracer.
+func NewBridgeTracer(opts ...BridgeOption) *BridgeTracer {
+	noopHandler := func(msg string) {}
 	return &BridgeTracer{
 		setTracer: bridgeSetTracer{
-			otelTracer: noop.Tracer,
+			otelTracer:     noop.Tracer,
+			warningHandler: noopHandler,
 		},
-		warningHandler: func(msg string) {},
-		propagator:     nil,
+		config:         newConfig(opts...),
+		warningHandler: noopHandler,
 	}
 }
 
@@ -329,7 +930,7 @@ func (t *BridgeTracer) SetOpenTelemetryTracer(tracer otel.Tracer) {
 }
 
 func (t *BridgeTracer) SetTextMapPropagator(propagator otel.TextMapPropagator) {
-	t.propagator = propagator
+	t.propagator.Store(propagatorHolder{propagator})
 }
 
 func (t *BridgeTracer) NewHookedContext(ctx context.Context) context.Context {
@@ -354,10 +955,9 @@ func (t *BridgeTracer) baggageSetHook(ctx context.Context) context.Context {
 	// context, so we don't care about the old hooks.
 	clearCtx, _, _ := baggage.ContextWithNoHooks(ctx)
 	m := baggage.MapFromContext(clearCtx)
-	m.Foreach(func(kv label.KeyValue) bool {
+	for _, kv := range sortedBaggage(m) {
 		bSpan.setBaggageItemOnly(string(kv.Key), kv.Value.Emit())
-		return true
-	})
+	}
 	return ctx
 }
 
@@ -372,14 +972,15 @@ func (t *BridgeTracer) baggageGetHook(ctx context.Context, m baggage.Map) baggag
 		t.warningHandler("Encountered a foreign OpenTracing span, will not propagate the baggage items from OpenTracing span context\n")
 		return m
 	}
-	items := bSpan.extraBaggageItems
-	if len(items) == 0 {
-		return m
-	}
-	kv := make([]label.KeyValue, 0, len(items))
-	for k, v := range items {
+	bSpan.baggageLock.Lock()
+	kv := make([]label.KeyValue, 0, len(bSpan.extraBaggageItems))
+	for k, v := range bSpan.extraBaggageItems {
 		kv = append(kv, label.String(k, v))
 	}
+	bSpan.baggageLock.Unlock()
+	if len(kv) == 0 {
+		return m
+	}
 	return m.Apply(baggage.MapUpdate{MultiKV: kv})
 }
 
@@ -390,29 +991,42 @@ func (t *BridgeTracer) StartSpan(operationName string, opts ...ot.StartSpanOptio
 	for _, opt := range opts {
 		opt.Apply(&sso)
 	}
+	if operationName == "" && t.config.defaultSpanName != "" {
+		operationName = t.config.defaultSpanName
+		t.emptyNameWarnOnce.Do(func() {
+			t.warningHandler(fmt.Sprintf("Span started with an empty operation name, using %q instead\n", operationName))
+		})
+	}
 	parentBridgeSC, links := otSpanReferencesToParentAndLinks(sso.References)
-	attributes, kind, hadTrueErrorTag := otTagsToOTelAttributesKindAndError(sso.Tags)
+	tags, eventTags := t.splitEventTags(sso.Tags)
+	attributes, kind, hadTrueErrorTag := otTagsToOTelAttributesKindAndError(tags, t.config)
+	dropped := hasZeroSamplingPriority(sso.Tags) || t.config.recordPolicy == NeverRecord
 	checkCtx := migration.WithDeferredSetup(context.Background())
 	if parentBridgeSC != nil {
-		checkCtx = otel.ContextWithRemoteSpanContext(checkCtx, parentBridgeSC.otelSpanContext)
+		parentSC := parentBridgeSC.otelSpanContext
+		if dropped {
+			parentSC.TraceFlags &^= otel.FlagsSampled
+		}
+		checkCtx = otel.ContextWithRemoteSpanContext(checkCtx, parentSC)
 	}
-	checkCtx2, otelSpan := t.setTracer.tracer().Start(
-		checkCtx,
-		operationName,
+	spanOpts := []otel.SpanOption{
 		otel.WithAttributes(attributes...),
 		otel.WithTimestamp(sso.StartTime),
 		otel.WithLinks(links...),
-		otel.WithRecord(),
 		otel.WithSpanKind(kind),
-	)
-	if checkCtx != checkCtx2 {
+	}
+	if !dropped && t.config.recordPolicy == AlwaysRecord {
+		spanOpts = append(spanOpts, otel.WithRecord())
+	}
+	tracer := t.setTracer.tracer()
+	checkCtx2, otelSpan := tracer.Start(checkCtx, operationName, spanOpts...)
+	// Only tracers implementing the extension promise to defer the
+	// context setup, others are free to return any context.
+	if _, ok := tracer.(migration.DeferredContextSetupTracerExtension); ok && checkCtx != checkCtx2 {
 		t.warnOnce.Do(func() {
 			t.warningHandler("SDK should have deferred the context setup, see the documentation of go.opentelemetry.io/otel/bridge/opentracing/migration\n")
 		})
 	}
-	if hadTrueErrorTag {
-		otelSpan.SetStatus(codes.Error, "")
-	}
 	// One does not simply pass a concrete pointer to function
 	// that takes some interface. In case of passing nil concrete
 	// pointer, we get an interface with non-nil type (because the
@@ -423,12 +1037,72 @@ func (t *BridgeTracer) StartSpan(operationName string, opts ...ot.StartSpanOptio
 	if parentBridgeSC != nil {
 		otSpanContext = parentBridgeSC
 	}
-	sctx := newBridgeSpanContext(otelSpan.SpanContext(), otSpanContext)
-	span := newBridgeSpan(otelSpan, sctx, t)
+	sctx := newBridgeSpanContext(otelSpan.SpanContext(), otSpanContext, t.config)
+	span := newBridgeSpan(otelSpan, sctx, t, kind)
+	span.name = operationName
+	span.remoteParent = parentBridgeSC != nil && parentBridgeSC.remote
+	if len(eventTags) > 0 {
+		span.addEvent(tagsEventName, otel.WithTimestamp(sso.StartTime), otel.WithAttributes(eventTags...))
+	}
+	for k, v := range sso.Tags {
+		span.setErrorDescriptionTag(k, v)
+	}
+	if hadTrueErrorTag {
+		span.setErrorFromTag()
+	}
+	if v, ok := sso.Tags[string(otext.HTTPStatusCode)]; ok && t.config.httpStatusToSpanStatus {
+		span.setStatusFromHTTPStatus(v)
+	}
+	if v, ok := tags[StatusCodeTagKey]; ok {
+		span.setStatusFromTag(v)
+	}
 
 	return span
 }
 
+// StartChildFromExtracted starts a span that is a child of the passed
+// span context, usually returned by Extract, and returns it with a copy
+// of ctx in which it is the active span for both the OpenTracing and
+// the OpenTelemetry APIs. It shortens the common server-side pattern
+// of extracting the span context of a request and continuing the
+// trace. The span is started without a parent if parent is nil. The
+// passed options are applied after the ChildOf reference, so a later
+// ChildOf reference becomes a link.
+func (t *BridgeTracer) StartChildFromExtracted(ctx context.Context, operationName string, parent ot.SpanContext, opts ...ot.StartSpanOption) (ot.Span, context.Context) {
+	spanOpts := make([]ot.StartSpanOption, 0, len(opts)+1)
+	spanOpts = append(spanOpts, ot.ChildOf(parent))
+	spanOpts = append(spanOpts, opts...)
+	span := t.StartSpan(operationName, spanOpts...)
+	ctx = ot.ContextWithSpan(ctx, span)
+	if bSpan, ok := span.(*bridgeSpan); ok && otel.SpanFromContext(ctx).SpanContext() != bSpan.otelSpan.SpanContext() {
+		ctx = otel.ContextWithSpan(ctx, bSpan.otelSpan)
+	}
+	return span, ctx
+}
+
+// hasZeroSamplingPriority returns whether the sampling.priority tag is
+// set to zero, which OpenTracing uses to ask for the span to be dropped.
+// Such a span is not forced to record and the sampled flag of its
+// parent is not passed to the OpenTelemetry tracer, so the SDK can drop
+// it.
+func hasZeroSamplingPriority(tags ot.Tags) bool {
+	v, ok := tags[string(otext.SamplingPriority)]
+	if !ok {
+		return false
+	}
+	priority, ok := intFromTag(v)
+	return ok && priority == 0
+}
+
+// resetWarnings makes the warnings that are emitted only once emitted
+// again. It must not be called concurrently with other methods of t.
+func (t *BridgeTracer) resetWarnings() {
+	t.warnOnce = sync.Once{}
+	t.emptyNameWarnOnce = sync.Once{}
+	t.incomingBaggageWarnOnce = sync.Once{}
+	t.setTracer.warnOnce = sync.Once{}
+}
+
 // ContextWithBridgeSpan sets up the context with the passed
 // OpenTelemetry span as the active OpenTracing span.
 //
@@ -440,12 +1114,24 @@ func (t *BridgeTracer) ContextWithBridgeSpan(ctx context.Context, span otel.Span
 	if parentSpan := ot.SpanFromContext(ctx); parentSpan != nil {
 		otSpanContext = parentSpan.Context()
 	}
-	bCtx := newBridgeSpanContext(span.SpanContext(), otSpanContext)
-	bSpan := newBridgeSpan(span, bCtx, t)
+	bCtx := newBridgeSpanContext(span.SpanContext(), otSpanContext, t.config)
+	bSpan := newBridgeSpan(span, bCtx, t, otel.SpanKindInternal)
 	bSpan.skipDeferHook = true
 	return ot.ContextWithSpan(ctx, bSpan)
 }
 
+// SpanContextFromContext returns the OpenTelemetry span context of
+// the active OpenTracing span in the passed context. The returned
+// boolean is false if there is no active OpenTracing span or if the
+// span was not created by a BridgeTracer.
+func (t *BridgeTracer) SpanContextFromContext(ctx context.Context) (otel.SpanContext, bool) {
+	bSpan, ok := ot.SpanFromContext(ctx).(*bridgeSpan)
+	if !ok {
+		return otel.SpanContext{}, false
+	}
+	return bSpan.ctx.otelSpanContext, true
+}
+
 // ContextWithSpanHook is an implementation of the OpenTracing tracer
 // extension interface. It will call the DeferredContextSetupHook
 // function on the tracer if it implements the
@@ -465,62 +1151,109 @@ func (t *BridgeTracer) ContextWithSpanHook(ctx context.Context, span ot.Span) co
 	return ctx
 }
 
-func otTagsToOTelAttributesKindAndError(tags map[string]interface{}) ([]label.KeyValue, otel.SpanKind, bool) {
+// splitEventTags separates the tags that should be recorded as a span
+// event from the rest.
+func (t *BridgeTracer) splitEventTags(tags map[string]interface{}) (map[string]interface{}, []label.KeyValue) {
+	if len(t.config.eventTags) == 0 {
+		return tags, nil
+	}
+	var eventTags []label.KeyValue
+	spanTags := make(map[string]interface{}, len(tags))
+	for k, v := range tags {
+		switch k {
+		case string(otext.SpanKind), string(otext.Error), StatusCodeTagKey, StatusDescriptionTagKey:
+			// Not recorded as attributes, so never routed to events.
+			spanTags[k] = v
+			continue
+		}
+		if t.config.isEventTag(k) {
+			eventTags = append(eventTags, t.config.otTagToOTelLabels(k, v)...)
+		} else {
+			spanTags[k] = v
+		}
+	}
+	return spanTags, eventTags
+}
+
+func otTagsToOTelAttributesKindAndError(tags map[string]interface{}, conf config) ([]label.KeyValue, otel.SpanKind, bool) {
 	kind := otel.SpanKindInternal
 	err := false
 	var pairs []label.KeyValue
 	for k, v := range tags {
 		switch k {
 		case string(otext.SpanKind):
-			if s, ok := v.(string); ok {
-				switch strings.ToLower(s) {
-				case "client":
-					kind = otel.SpanKindClient
-				case "server":
-					kind = otel.SpanKindServer
-				case "producer":
-					kind = otel.SpanKindProducer
-				case "consumer":
-					kind = otel.SpanKindConsumer
-				}
+			// The helpers from the ext package set the tag as a
+			// SpanKindEnum, others set it as a plain string.
+			var s string
+			switch val := v.(type) {
+			case string:
+				s = val
+			case otext.SpanKindEnum:
+				s = string(val)
+			}
+			switch strings.ToLower(s) {
+			case "client":
+				kind = otel.SpanKindClient
+			case "server":
+				kind = otel.SpanKindServer
+			case "producer":
+				kind = otel.SpanKindProducer
+			case "consumer":
+				kind = otel.SpanKindConsumer
 			}
 		case string(otext.Error):
 			if b, ok := v.(bool); ok && b {
 				err = true
 			}
+		case StatusCodeTagKey, StatusDescriptionTagKey:
+			// Handled once the span is created.
 		default:
-			pairs = append(pairs, otTagToOTelLabel(k, v))
+			pairs = append(pairs, conf.otTagToOTelLabels(k, v)...)
 		}
 	}
 	return pairs, kind, err
 }
 
+// otTagToOTelLabel converts a tag to a label with label.Any, so the
+// values keep their type when there is a label counterpart.
 func otTagToOTelLabel(k string, v interface{}) label.KeyValue {
-	key := otTagToOTelLabelKey(k)
-	switch val := v.(type) {
-	case bool:
-		return key.Bool(val)
-	case int64:
-		return key.Int64(val)
-	case uint64:
-		return key.Uint64(val)
-	case float64:
-		return key.Float64(val)
-	case int32:
-		return key.Int32(val)
-	case uint32:
-		return key.Uint32(val)
-	case float32:
-		return key.Float32(val)
-	case int:
-		return key.Int(val)
-	case uint:
-		return key.Uint(val)
-	case string:
-		return key.String(val)
-	default:
-		return key.String(fmt.Sprint(v))
+	return label.Any(string(otTagToOTelLabelKey(k)), v)
+}
+
+// semanticTagKeys maps the OpenTracing tag keys that differ from the
+// OpenTelemetry semantic conventions to their semantic convention
+// counterparts.
+var semanticTagKeys = map[string]label.Key{
+	string(otext.PeerHostname):          semconv.NetPeerNameKey,
+	string(otext.PeerHostIPv4):          semconv.NetPeerIPKey,
+	string(otext.PeerHostIPv6):          semconv.NetPeerIPKey,
+	string(otext.PeerPort):              semconv.NetPeerPortKey,
+	string(otext.DBInstance):            semconv.DBNameKey,
+	string(otext.DBType):                semconv.DBSystemKey,
+	string(otext.MessageBusDestination): semconv.MessagingDestinationKey,
+}
+
+// otTagToOTelLabels converts the tag to labels, applying the semantic
+// convention mapping if it is enabled. Unless configured otherwise,
+// the tag key is matched case-insensitively.
+func (c config) otTagToOTelLabels(k string, v interface{}) []label.KeyValue {
+	kv := otTagToOTelLabel(k, v)
+	if !c.semanticTags {
+		return []label.KeyValue{kv}
 	}
+	lookupKey := k
+	if !c.caseSensitiveTagMapping {
+		lookupKey = strings.ToLower(k)
+	}
+	semKey, ok := semanticTagKeys[lookupKey]
+	if !ok {
+		return []label.KeyValue{kv}
+	}
+	semKV := label.KeyValue{Key: semKey, Value: kv.Value}
+	if c.keepOriginalTags {
+		return []label.KeyValue{semKV, kv}
+	}
+	return []label.KeyValue{semKV}
 }
 
 func otTagToOTelLabelKey(k string) label.Key {
@@ -543,6 +1276,7 @@ func otSpanReferencesToParentAndLinks(references []ot.SpanReference) (*bridgeSpa
 			// valid OTel SpanContext.
 			continue
 		}
+		links = append(links, bridgeSC.extractedLinks...)
 		if parent != nil {
 			links = append(links, otSpanReferenceToOTelLink(bridgeSC, reference.Type))
 		} else {
@@ -556,6 +1290,10 @@ func otSpanReferencesToParentAndLinks(references []ot.SpanReference) (*bridgeSpa
 	return parent, links
 }
 
+// otSpanReferenceToOTelLink converts the reference to a link. The
+// whole span context of the referenced span is used, including its
+// trace flags, so the sampling decision of the referenced span is
+// propagated through the link.
 func otSpanReferenceToOTelLink(bridgeSC *bridgeSpanContext, refType ot.SpanReferenceType) otel.Link {
 	return otel.Link{
 		SpanContext: bridgeSC.otelSpanContext,
@@ -565,7 +1303,31 @@ func otSpanReferenceToOTelLink(bridgeSC *bridgeSpanContext, refType ot.SpanRefer
 
 func otSpanReferenceTypeToOTelLinkAttributes(refType ot.SpanReferenceType) []label.KeyValue {
 	return []label.KeyValue{
-		label.String("ot-span-reference-type", otSpanReferenceTypeToString(refType)),
+		spanReferenceTypeKey.String(otSpanReferenceTypeToString(refType)),
+	}
+}
+
+// spanReferenceTypeKey is the key of the link attribute holding the
+// type of the OpenTracing reference the link was converted from.
+const spanReferenceTypeKey = label.Key("ot-span-reference-type")
+
+// LinkToReference converts an OpenTelemetry link to an OpenTracing
+// reference to the linked span context, which can be passed when
+// starting a span with a BridgeTracer. The reference type is taken from
+// the ot-span-reference-type attribute of the links converted from
+// references by the BridgeTracer: ChildOfRef for "extra-child-of" and
+// FollowsFromRef otherwise, as a link does not make the linked span a
+// parent.
+func LinkToReference(link otel.Link) ot.SpanReference {
+	refType := ot.FollowsFromRef
+	for _, kv := range link.Attributes {
+		if kv.Key == spanReferenceTypeKey && kv.Value.AsString() == otSpanReferenceTypeToString(ot.ChildOfRef) {
+			refType = ot.ChildOfRef
+		}
+	}
+	return ot.SpanReference{
+		Type:              refType,
+		ReferencedContext: newBridgeSpanContext(link.SpanContext, nil, config{}),
 	}
 }
 
@@ -594,65 +1356,324 @@ func (s fakeSpan) SpanContext() otel.SpanContext {
 	return s.sc
 }
 
+// invalidInjection handles an Inject call with an invalid span context
+// according to the InjectValidationMode.
+func (t *BridgeTracer) invalidInjection(sm ot.SpanContext) error {
+	switch t.config.injectValidation {
+	case InjectValidationSilentNoop:
+		return nil
+	case InjectValidationPanic:
+		panic(fmt.Sprintf("opentracing bridge: invalid span context %#v passed to Inject", sm))
+	default:
+		return ot.ErrInvalidSpanContext
+	}
+}
+
 // Inject is a part of the implementation of the OpenTracing Tracer
 // interface.
 //
-// Currently only the HTTPHeaders format is supported.
+// A span context that was not created by a BridgeTracer or that is
+// invalid is handled as configured with WithStrictInjectValidation.
+//
+// If the span context was extracted by the BridgeTracer or descends
+// from an extracted one, the W3C tracestate extracted next to the span
+// context is injected again. No other value of the extraction context
+// is passed to the propagator.
+//
+// The HTTPHeaders and the Binary formats are supported. With the
+// Binary format the carrier must be an io.Writer, to which the fields
+// set by the propagator are written as a single length-prefixed frame,
+// so several span contexts can be written to the same stream.
 func (t *BridgeTracer) Inject(sm ot.SpanContext, format interface{}, carrier interface{}) error {
+	return t.InjectWithOptions(sm, format, carrier)
+}
+
+// InjectWithOptions works like Inject, with the passed options applied
+// to this call only. For example WithoutBaggage keeps the baggage of
+// the span context from crossing a trust boundary while the trace
+// still continues on the other side.
+func (t *BridgeTracer) InjectWithOptions(sm ot.SpanContext, format interface{}, carrier interface{}, opts ...InjectOption) error {
 	bridgeSC, ok := sm.(*bridgeSpanContext)
-	if !ok {
-		return ot.ErrInvalidSpanContext
+	if !ok || !bridgeSC.otelSpanContext.IsValid() {
+		return t.invalidInjection(sm)
 	}
-	if !bridgeSC.otelSpanContext.IsValid() {
-		return ot.ErrInvalidSpanContext
+	builtinFormat, ok := format.(ot.BuiltinFormat)
+	if !ok {
+		return ot.ErrUnsupportedFormat
 	}
-	if builtinFormat, ok := format.(ot.BuiltinFormat); !ok || builtinFormat != ot.HTTPHeaders {
+	switch builtinFormat {
+	case ot.HTTPHeaders:
+		hhcarrier, ok := carrier.(ot.HTTPHeadersCarrier)
+		if !ok {
+			return ot.ErrInvalidCarrier
+		}
+		t.inject(bridgeSC, http.Header(hhcarrier), newInjectConfig(opts...))
+		return nil
+	case ot.Binary:
+		w, ok := carrier.(io.Writer)
+		if !ok {
+			return ot.ErrInvalidCarrier
+		}
+		header := http.Header{}
+		t.inject(bridgeSC, header, newInjectConfig(opts...))
+		return writeBinaryFrame(w, header)
+	default:
 		return ot.ErrUnsupportedFormat
 	}
-	hhcarrier, ok := carrier.(ot.HTTPHeadersCarrier)
-	if !ok {
-		return ot.ErrInvalidCarrier
+}
+
+// InjectFromContext works like Inject with the span context of the
+// active span in ctx, for callers that only have a context. The active
+// OpenTracing span created by a BridgeTracer is used if any. Otherwise
+// the span context of the active OpenTelemetry span, or the remote span
+// context of ctx if there is no active span, is injected, with the
+// baggage of ctx. It returns opentracing.ErrSpanContextNotFound if ctx
+// has none of them.
+func (t *BridgeTracer) InjectFromContext(ctx context.Context, format interface{}, carrier interface{}) error {
+	if bSpan, ok := ot.SpanFromContext(ctx).(*bridgeSpan); ok {
+		return t.Inject(bSpan.Context(), format, carrier)
+	}
+	otelSC, remote, _ := otelparent.GetSpanContextAndLinks(ctx, false)
+	if !otelSC.IsValid() {
+		return ot.ErrSpanContextNotFound
+	}
+	bridgeSC := newBridgeSpanContext(otelSC, nil, t.config)
+	bridgeSC.remote = remote
+	bridgeSC.tracestate = propagators.TracestateFromContext(ctx)
+	members := propagators.BaggageMembersFromContext(ctx)
+	for _, kv := range sortedBaggage(baggage.MapFromContext(ctx)) {
+		bridgeSC.setExtractedBaggageEntry(string(kv.Key), extractedBaggageEntry(kv, members))
 	}
-	header := http.Header(hhcarrier)
+	return t.Inject(bridgeSC, format, carrier)
+}
+
+// inject injects the passed span context into the carrier with the
+// propagator of t.
+func (t *BridgeTracer) inject(bridgeSC *bridgeSpanContext, carrier otel.TextMapCarrier, conf injectConfig) {
 	fs := fakeSpan{
 		Span: noop.Span,
 		sc:   bridgeSC.otelSpanContext,
 	}
-	ctx := otel.ContextWithSpan(context.Background(), fs)
-	ctx = baggage.ContextWithMap(ctx, bridgeSC.baggageItems)
+	ctx := context.Background()
+	if bridgeSC.tracestate != "" {
+		ctx = propagators.ContextWithTracestate(ctx, bridgeSC.tracestate)
+	}
+	ctx = otel.ContextWithSpan(ctx, fs)
+	if conf.withoutBaggage {
+		ctx = baggage.ContextWithMap(ctx, baggage.NewEmptyMap())
+		ctx = propagators.ContextWithBaggageMembers(ctx, nil)
+	} else {
+		ctx = t.contextWithBaggage(ctx, bridgeSC)
+	}
+	t.getPropagator().Inject(ctx, carrier)
+}
+
+// InjectToMap returns all the values the propagator of t injects for
+// the passed span context, like the traceparent, tracestate and
+// baggage headers, keyed by the names the propagator uses. It goes
+// through the same path as Inject, so it suits logging or handing the
+// trace over to another system. A span context that was not created
+// by a BridgeTracer or that is invalid is handled as configured with
+// WithStrictInjectValidation, an empty map being returned in the
+// InjectValidationSilentNoop mode.
+func (t *BridgeTracer) InjectToMap(sm ot.SpanContext) (map[string]string, error) {
+	bridgeSC, ok := sm.(*bridgeSpanContext)
+	if !ok || !bridgeSC.otelSpanContext.IsValid() {
+		if err := t.invalidInjection(sm); err != nil {
+			return nil, err
+		}
+		return map[string]string{}, nil
+	}
+	carrier := propagators.MapCarrier{}
+	t.inject(bridgeSC, carrier, injectConfig{})
+	return carrier, nil
+}
+
+// injectedBaggage returns the baggage injected for the passed span
+// context, including the sampled flag if WithSampledBaggageKey is
+// used. It is empty if WithBaggageDirection disables outbound baggage.
+func (t *BridgeTracer) injectedBaggage(sc *bridgeSpanContext) baggage.Map {
+	if !t.config.baggageDirection.outbound() {
+		return baggage.NewEmptyMap()
+	}
+	m := sc.injectedBaggage()
+	if key := t.config.sampledBaggageKey; key != "" {
+		sampled := "0"
+		if sc.otelSpanContext.IsSampled() {
+			sampled = "1"
+		}
+		m = m.Apply(baggage.MapUpdate{SingleKV: label.String(key, sampled)})
+	}
+	return m
+}
+
+// contextWithBaggage returns a copy of ctx holding the baggage injected
+// for the passed span context, with the properties of its items.
+func (t *BridgeTracer) contextWithBaggage(ctx context.Context, sc *bridgeSpanContext) context.Context {
+	ctx = baggage.ContextWithMap(ctx, t.injectedBaggage(sc))
+	var members map[string]propagators.BaggageMember
+	if t.config.baggageDirection.outbound() {
+		members = sc.baggageMembers()
+	}
+	return propagators.ContextWithBaggageMembers(ctx, members)
+}
+
+// BaggageHeader returns the headers the propagator of t injects for
+// the baggage of the passed span context alone, without the trace
+// context. It returns opentracing.ErrInvalidSpanContext if the span
+// context was not created by a BridgeTracer.
+func (t *BridgeTracer) BaggageHeader(sm ot.SpanContext) (http.Header, error) {
+	bridgeSC, ok := sm.(*bridgeSpanContext)
+	if !ok {
+		return nil, ot.ErrInvalidSpanContext
+	}
+	header := http.Header{}
+	ctx := t.contextWithBaggage(context.Background(), bridgeSC)
 	t.getPropagator().Inject(ctx, header)
-	return nil
+	return header, nil
 }
 
 // Extract is a part of the implementation of the OpenTracing Tracer
 // interface.
 //
-// Currently only the HTTPHeaders format is supported.
+// If the propagator puts both a current span and a remote span
+// context in the Go context, the span context of the current span is
+// used and the remote one is available through the ExtractedLinks
+// function.
+//
+// The HTTPHeaders and the Binary formats are supported. With the
+// Binary format the carrier must be an io.Reader, from which exactly
+// one frame written by Inject is read, leaving the reader positioned
+// at the next one. opentracing.ErrSpanContextNotFound is returned at
+// the end of the stream and opentracing.ErrSpanContextCorrupted for a
+// truncated frame.
 func (t *BridgeTracer) Extract(format interface{}, carrier interface{}) (ot.SpanContext, error) {
-	if builtinFormat, ok := format.(ot.BuiltinFormat); !ok || builtinFormat != ot.HTTPHeaders {
-		return nil, ot.ErrUnsupportedFormat
-	}
-	hhcarrier, ok := carrier.(ot.HTTPHeadersCarrier)
-	if !ok {
-		return nil, ot.ErrInvalidCarrier
+	return t.ExtractWithContext(context.Background(), format, carrier)
+}
+
+// ExtractWithContext works like Extract, but the baggage of the
+// passed context is merged with the extracted baggage. If both have
+// an item with the same key, the extracted value wins. The span
+// contexts in the passed context are ignored, so an active span of
+// the caller never becomes the extracted span context. The propagator
+// extracts into the passed context, see ExtractContext.
+func (t *BridgeTracer) ExtractWithContext(ctx context.Context, format interface{}, carrier interface{}) (ot.SpanContext, error) {
+	_, sc, err := t.ExtractContext(ctx, format, carrier)
+	return sc, err
+}
+
+// ExtractContext works like ExtractWithContext and also returns the
+// context the propagator extracted into. The propagator extracts into
+// the passed context instead of an empty one, so propagators reading
+// values of the context, like request scoped data, work. The returned
+// context holds the values and the deadline of the passed context next
+// to the extracted remote span context and baggage. The active span of
+// the passed context is hidden in it, so spans started with the
+// returned context are children of the extracted span context. The
+// passed context is returned on error.
+func (t *BridgeTracer) ExtractContext(ctx context.Context, format interface{}, carrier interface{}) (context.Context, ot.SpanContext, error) {
+	header, err := extractionHeader(format, carrier)
+	if err != nil {
+		return ctx, nil, err
 	}
-	header := http.Header(hhcarrier)
-	ctx := t.getPropagator().Extract(context.Background(), header)
-	baggage := baggage.MapFromContext(ctx)
+	callerCtx := ctx
+	callerBaggage := baggage.MapFromContext(ctx)
+	ctx = otel.ContextWithSpan(ctx, noop.Span)
+	ctx = otel.ContextWithRemoteSpanContext(ctx, otel.SpanContext{})
+	ctx = t.getPropagator().Extract(ctx, header)
 	otelSC, _, _ := otelparent.GetSpanContextAndLinks(ctx, false)
-	bridgeSC := &bridgeSpanContext{
-		baggageItems:    baggage,
-		otelSpanContext: otelSC,
+	extractedBaggage := baggage.MapFromContext(ctx)
+	if !t.config.baggageDirection.inbound() {
+		extractedBaggage = baggage.NewEmptyMap()
+		ctx = baggage.ContextWithMap(ctx, callerBaggage)
+	}
+	if key := label.Key(t.config.sampledBaggageKey); key != "" {
+		if v, ok := extractedBaggage.Value(key); ok && v.Emit() == "1" {
+			otelSC.TraceFlags |= otel.FlagsSampled
+		}
+		extractedBaggage = extractedBaggage.Apply(baggage.MapUpdate{DropSingleK: key})
+	}
+	bridgeSC := newBridgeSpanContext(otelSC, nil, t.config)
+	bridgeSC.tracestate = propagators.TracestateFromContext(ctx)
+	bridgeSC.remote = true
+	if rsc := otel.RemoteSpanContextFromContext(ctx); rsc.IsValid() && rsc != otelSC {
+		bridgeSC.extractedLinks = append(bridgeSC.extractedLinks, otel.Link{
+			SpanContext: rsc,
+			Attributes:  []label.KeyValue{extractedLinkKey.String("remote")},
+		})
+	}
+	members := propagators.BaggageMembersFromContext(ctx)
+	refusedItems := 0
+	setBaggage := func(kv label.KeyValue) {
+		if !bridgeSC.setExtractedBaggageEntry(string(kv.Key), extractedBaggageEntry(kv, members)) {
+			refusedItems++
+		}
+	}
+	for _, kv := range sortedBaggage(callerBaggage) {
+		setBaggage(kv)
+	}
+	incomingSize, droppedItems := 0, 0
+	for _, kv := range sortedBaggage(extractedBaggage) {
+		if max := t.config.maxIncomingBaggageSize; max > 0 {
+			incomingSize += len(kv.Key) + len(kv.Value.Emit())
+			if incomingSize > max {
+				droppedItems++
+				continue
+			}
+		}
+		setBaggage(kv)
+	}
+	if refusedItems > 0 {
+		t.warningHandler(fmt.Sprintf("Extracted baggage does not fit in the maximum baggage size or number of items, dropped %d items\n", refusedItems))
+	}
+	if droppedItems > 0 {
+		t.incomingBaggageWarnOnce.Do(func() {
+			t.warningHandler(fmt.Sprintf("Extracted baggage exceeds the maximum incoming size of %d bytes, dropped %d items\n", t.config.maxIncomingBaggageSize, droppedItems))
+		})
 	}
 	if !bridgeSC.otelSpanContext.IsValid() {
-		return nil, ot.ErrSpanContextNotFound
+		return callerCtx, nil, ot.ErrSpanContextNotFound
+	}
+	return ctx, bridgeSC, nil
+}
+
+// extractionHeader returns the fields of the carrier for the propagator
+// to extract from.
+func extractionHeader(format interface{}, carrier interface{}) (http.Header, error) {
+	builtinFormat, ok := format.(ot.BuiltinFormat)
+	if !ok {
+		return nil, ot.ErrUnsupportedFormat
 	}
-	return bridgeSC, nil
+	switch builtinFormat {
+	case ot.HTTPHeaders:
+		hhcarrier, ok := carrier.(ot.HTTPHeadersCarrier)
+		if !ok {
+			return nil, ot.ErrInvalidCarrier
+		}
+		return http.Header(hhcarrier), nil
+	case ot.Binary:
+		r, ok := carrier.(io.Reader)
+		if !ok {
+			return nil, ot.ErrInvalidCarrier
+		}
+		return readBinaryFrame(r)
+	default:
+		return nil, ot.ErrUnsupportedFormat
+	}
+}
+
+// PropagationFields returns the keys of the headers the propagator
+// of t sets in Inject, like the Fields method of the propagator. The
+// propagator is the one set with SetTextMapPropagator or else the
+// global one. Middleware can use it to strip the trace headers of a
+// request before forwarding it.
+func (t *BridgeTracer) PropagationFields() []string {
+	return t.getPropagator().Fields()
 }
 
 func (t *BridgeTracer) getPropagator() otel.TextMapPropagator {
-	if t.propagator != nil {
-		return t.propagator
+	if h, ok := t.propagator.Load().(propagatorHolder); ok && h.propagator != nil {
+		return h.propagator
 	}
 	return otelglobal.TextMapPropagator()
 }
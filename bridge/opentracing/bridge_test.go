@@ -0,0 +1,2747 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opentracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	ot "github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/bridge/opentracing/internal"
+	"go.opentelemetry.io/otel/bridge/opentracing/migration"
+	"go.opentelemetry.io/otel/codes"
+	otelglobal "go.opentelemetry.io/otel/global"
+	"go.opentelemetry.io/otel/internal/baggage"
+	"go.opentelemetry.io/otel/internal/trace/noop"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/propagators"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+func newTestBridgeTracer(opts ...BridgeOption) (*BridgeTracer, *oteltest.StandardSpanRecorder) {
+	sr := new(oteltest.StandardSpanRecorder)
+	tp := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+	bt := NewBridgeTracer(opts...)
+	bt.SetOpenTelemetryTracer(tp.Tracer(""))
+	return bt, sr
+}
+
+func baggageItems(sc ot.SpanContext) map[string]string {
+	items := make(map[string]string)
+	sc.ForeachBaggageItem(func(k, v string) bool {
+		items[k] = v
+		return true
+	})
+	return items
+}
+
+func TestBaggageTruncationPolicy(t *testing.T) {
+	// Every item below is 4 bytes big: a 2 byte key and a 2 byte value.
+	testCases := []struct {
+		name   string
+		policy BaggageTruncationPolicy
+		keys   []string
+		want   map[string]string
+	}{
+		{
+			name:   "refuse new at the boundary",
+			policy: BaggageTruncationRefuseNew,
+			keys:   []string{"Aa", "Bb"},
+			want:   map[string]string{"Aa": "aa", "Bb": "bb"},
+		},
+		{
+			name:   "refuse new over the boundary",
+			policy: BaggageTruncationRefuseNew,
+			keys:   []string{"Aa", "Bb", "Cc"},
+			want:   map[string]string{"Aa": "aa", "Bb": "bb"},
+		},
+		{
+			name:   "drop oldest at the boundary",
+			policy: BaggageTruncationDropOldest,
+			keys:   []string{"Aa", "Bb"},
+			want:   map[string]string{"Aa": "aa", "Bb": "bb"},
+		},
+		{
+			name:   "drop oldest over the boundary",
+			policy: BaggageTruncationDropOldest,
+			keys:   []string{"Aa", "Bb", "Cc"},
+			want:   map[string]string{"Bb": "bb", "Cc": "cc"},
+		},
+		{
+			name:   "drop oldest after update",
+			policy: BaggageTruncationDropOldest,
+			keys:   []string{"Aa", "Bb", "Aa", "Cc"},
+			want:   map[string]string{"Aa": "aa", "Cc": "cc"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var warnings []string
+			bt, _ := newTestBridgeTracer(
+				WithMaxBaggageSize(8),
+				WithBaggageTruncationPolicy(tc.policy),
+			)
+			span := bt.StartSpan("test")
+			bt.SetWarningHandler(func(msg string) { warnings = append(warnings, msg) })
+			for _, k := range tc.keys {
+				span.SetBaggageItem(k, string([]byte{k[0] + 'a' - 'A', k[1]}))
+			}
+			got := baggageItems(span.Context())
+			if len(got) != len(tc.want) {
+				t.Fatalf("got baggage %v, want %v", got, tc.want)
+			}
+			for k, v := range tc.want {
+				if got[k] != v {
+					t.Errorf("got baggage %v, want %v", got, tc.want)
+				}
+			}
+			if size := BaggageSize(span.Context()); size > 8 {
+				t.Errorf("baggage size %d exceeds the limit", size)
+			}
+			refused := tc.policy == BaggageTruncationRefuseNew && len(tc.keys) > len(tc.want)
+			if refused != (len(warnings) > 0) {
+				t.Errorf("unexpected warnings: %v", warnings)
+			}
+		})
+	}
+}
+
+func TestMaxBaggageItems(t *testing.T) {
+	// The items are far below the size limit, only the number of
+	// items is exceeded.
+	testCases := []struct {
+		name   string
+		policy BaggageTruncationPolicy
+		want   map[string]string
+	}{
+		{
+			name:   "refuse new",
+			policy: BaggageTruncationRefuseNew,
+			want:   map[string]string{"Aa": "aa", "Bb": "bb"},
+		},
+		{
+			name:   "drop oldest",
+			policy: BaggageTruncationDropOldest,
+			want:   map[string]string{"Bb": "bb", "Cc": "cc"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bt, _ := newTestBridgeTracer(
+				WithMaxBaggageSize(100),
+				WithMaxBaggageItems(2),
+				WithBaggageTruncationPolicy(tc.policy),
+			)
+			span := bt.StartSpan("test")
+			span.SetBaggageItem("Aa", "aa")
+			span.SetBaggageItem("Bb", "bb")
+			span.SetBaggageItem("Aa", "aa")
+			span.SetBaggageItem("Bb", "bb")
+			span.SetBaggageItem("Cc", "cc")
+			if got := baggageItems(span.Context()); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got baggage %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBaggageTruncationRefusesOversizedItem(t *testing.T) {
+	bt, _ := newTestBridgeTracer(
+		WithMaxBaggageSize(4),
+		WithBaggageTruncationPolicy(BaggageTruncationDropOldest),
+	)
+	span := bt.StartSpan("test")
+	span.SetBaggageItem("Aa", "aa")
+	span.SetBaggageItem("Bb", "too big")
+	if got := span.BaggageItem("Aa"); got != "aa" {
+		t.Errorf("existing item dropped for an item that can never fit, got %q", got)
+	}
+	if got := span.BaggageItem("Bb"); got != "unknown" {
+		t.Errorf("oversized item stored: %q", got)
+	}
+	if got := BaggageSize(span.Context()); got != 4 {
+		t.Errorf("got baggage size %d, want 4", got)
+	}
+}
+
+func TestExtractWarnsAboutBaggageSize(t *testing.T) {
+	var warnings []string
+	bt, _ := newTestBridgeTracer(WithMaxBaggageSize(8))
+	bt.SetWarningHandler(func(msg string) { warnings = append(warnings, msg) })
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	header.Set("otcorrelations", "aa=aa,bb=bb,cc=cc")
+	sc, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+	if got, want := baggageItems(sc), map[string]string{"aa": "aa", "bb": "bb"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got baggage %v, want %v", got, want)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "dropped 1 items") {
+		t.Errorf("got warnings %q, want one about the dropped item", warnings)
+	}
+}
+
+func TestSetTagArrayValues(t *testing.T) {
+	bt, sr := newTestBridgeTracer()
+	span := bt.StartSpan("test", ot.Tag{Key: "start.codes", Value: []string{"a", "b"}})
+	span.SetTag("http.status_codes", []int{200, 404})
+	span.SetTag("unsupported", []struct{}{{}})
+	span.Finish()
+
+	spans := sr.Completed()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	attrs := spans[0].Attributes()
+	for key, want := range map[label.Key]interface{}{
+		"start.codes":       [2]string{"a", "b"},
+		"http.status_codes": [2]int{200, 404},
+	} {
+		got := attrs[key]
+		if got.Type() != label.ARRAY {
+			t.Errorf("attribute %q: got type %v, want %v", key, got.Type(), label.ARRAY)
+			continue
+		}
+		if got.AsArray() != want {
+			t.Errorf("attribute %q: got %v, want %v", key, got.AsArray(), want)
+		}
+	}
+	if got := attrs["unsupported"]; got.Type() != label.STRING {
+		t.Errorf("unsupported array: got type %v, want %v", got.Type(), label.STRING)
+	}
+}
+
+func TestSpanContextFromContext(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+
+	if _, ok := bt.SpanContextFromContext(context.Background()); ok {
+		t.Error("got a span context from a context without an active span")
+	}
+
+	span := bt.StartSpan("test")
+	ctx := ot.ContextWithSpan(context.Background(), span)
+	sc, ok := bt.SpanContextFromContext(ctx)
+	if !ok {
+		t.Fatal("no span context found for an active bridge span")
+	}
+	if want := span.(*bridgeSpan).otelSpan.SpanContext(); sc != want {
+		t.Errorf("got span context %v, want %v", sc, want)
+	}
+
+	foreign := ot.NoopTracer{}.StartSpan("foreign")
+	if _, ok := bt.SpanContextFromContext(ot.ContextWithSpan(context.Background(), foreign)); ok {
+		t.Error("got a span context from a foreign OpenTracing span")
+	}
+}
+
+func TestHighCardinalityTagsAsEvents(t *testing.T) {
+	bt, sr := newTestBridgeTracer(WithHighCardinalityTagsAsEvents([]string{"request.id", "user.id"}))
+	span := bt.StartSpan("test", ot.Tags{"request.id": "r-1", "component": "db"})
+	span.SetTag("user.id", 42)
+	span.SetTag("peer.service", "backend")
+	span.Finish()
+
+	spans := sr.Completed()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	attrs := spans[0].Attributes()
+	for _, k := range []label.Key{"request.id", "user.id"} {
+		if _, ok := attrs[k]; ok {
+			t.Errorf("high cardinality tag %q recorded as a span attribute", k)
+		}
+	}
+	for _, k := range []label.Key{"component", "peer.service"} {
+		if _, ok := attrs[k]; !ok {
+			t.Errorf("tag %q not recorded as a span attribute", k)
+		}
+	}
+
+	events := spans[0].Events()
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	want := []map[label.Key]label.Value{
+		{"request.id": label.StringValue("r-1")},
+		{"user.id": label.IntValue(42)},
+	}
+	for i, event := range events {
+		if event.Name != tagsEventName {
+			t.Errorf("event %d: got name %q, want %q", i, event.Name, tagsEventName)
+		}
+		if len(event.Attributes) != len(want[i]) {
+			t.Errorf("event %d: got attributes %v, want %v", i, event.Attributes, want[i])
+		}
+		for k, v := range want[i] {
+			if event.Attributes[k] != v {
+				t.Errorf("event %d: got attributes %v, want %v", i, event.Attributes, want[i])
+			}
+		}
+	}
+}
+
+func TestHighCardinalityTagsRouting(t *testing.T) {
+	keys := []string{"request.id", string(otext.HTTPStatusCode), string(otext.Error)}
+	bt, sr := newTestBridgeTracer(WithHighCardinalityTagsAsEvents(keys), WithHTTPStatusToSpanStatus())
+
+	bt.StartSpan("start", ot.Tags{"request.id": "r-1", string(otext.HTTPStatusCode): 500}).Finish()
+	later := bt.StartSpan("later")
+	later.SetTag(string(otext.HTTPStatusCode), 503)
+	later.SetTag(string(otext.Error), true)
+	later.Finish()
+
+	for _, span := range sr.Completed() {
+		if got := span.StatusCode(); got != codes.Error {
+			t.Errorf("%s: got status %v, want %v", span.Name(), got, codes.Error)
+		}
+		attrs := span.Attributes()
+		for _, k := range keys {
+			if _, ok := attrs[label.Key(k)]; ok {
+				t.Errorf("%s: event tag %q recorded as a span attribute", span.Name(), k)
+			}
+		}
+		events := span.Events()
+		if len(events) != 1 {
+			t.Fatalf("%s: got %d events, want 1: %v", span.Name(), len(events), events)
+		}
+		if events[0].Name != tagsEventName {
+			t.Errorf("%s: got event %q, want %q", span.Name(), events[0].Name, tagsEventName)
+		}
+		if _, ok := events[0].Attributes[label.Key(otext.HTTPStatusCode)]; !ok {
+			t.Errorf("%s: got event attributes %v, want the HTTP status code", span.Name(), events[0].Attributes)
+		}
+	}
+	if got := len(sr.Completed()[0].Events()[0].Attributes); got != 2 {
+		t.Errorf("got %d attributes in the start event, want the 2 tags grouped", got)
+	}
+}
+
+func TestReferenceLinksKeepTraceFlags(t *testing.T) {
+	bt, sr := newTestBridgeTracer()
+	bt.SetTextMapPropagator(propagators.TraceContext{})
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	remote, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+
+	span := bt.StartSpan("test", ot.FollowsFrom(remote))
+	span.Finish()
+
+	links := sr.Completed()[0].LinksSlice()
+	if len(links) != 1 {
+		t.Fatalf("got %d links, want 1", len(links))
+	}
+	if !links[0].SpanContext.IsSampled() {
+		t.Error("the link to a sampled span context is not sampled")
+	}
+
+	out := http.Header{}
+	if err := bt.Inject(remote, ot.HTTPHeaders, ot.HTTPHeadersCarrier(out)); err != nil {
+		t.Fatalf("failed to inject the span context: %v", err)
+	}
+	if got, want := out.Get("traceparent"), header.Get("traceparent"); got != want {
+		t.Errorf("got traceparent %q, want %q", got, want)
+	}
+}
+
+func TestUnsetTracerWithoutWarningHandler(t *testing.T) {
+	bt := NewBridgeTracer()
+	span := bt.StartSpan("test")
+	span.Finish()
+	if _, ok := span.(*bridgeSpan); !ok {
+		t.Errorf("got span %T, want a bridge span", span)
+	}
+}
+
+func TestLazyLoggerEvaluation(t *testing.T) {
+	recordingTracer, sr := newTestBridgeTracer()
+	nonRecordingTracer := NewBridgeTracer()
+	nonRecordingTracer.SetOpenTelemetryTracer(noop.Tracer)
+	for _, tc := range []struct {
+		name   string
+		tracer *BridgeTracer
+		want   bool
+	}{
+		{name: "recording span", tracer: recordingTracer, want: true},
+		{name: "non-recording span", tracer: nonRecordingTracer, want: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			invoked := false
+			lazy := otlog.Lazy(func(fv otlog.Encoder) {
+				invoked = true
+				fv.EmitString("lazy", "value")
+			})
+
+			span := tc.tracer.StartSpan("test")
+			span.LogFields(lazy)
+			span.FinishWithOptions(ot.FinishOptions{
+				LogRecords: []ot.LogRecord{{Fields: []otlog.Field{lazy}}},
+			})
+			if invoked != tc.want {
+				t.Errorf("lazy logger invoked: %t, want %t", invoked, tc.want)
+			}
+		})
+	}
+
+	events := sr.Completed()[0].Events()
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	for _, event := range events {
+		if got := event.Attributes["lazy"]; got.AsString() != "value" {
+			t.Errorf("got lazy field value %q, want %q", got.AsString(), "value")
+		}
+	}
+}
+
+func TestLogFieldTypes(t *testing.T) {
+	bt, sr := newTestBridgeTracer()
+	span := bt.StartSpan("test")
+	span.LogFields(
+		otlog.String("string", "value"),
+		otlog.Bool("bool", true),
+		otlog.Int("int", -1),
+		otlog.Int32("int32", -32),
+		otlog.Int64("int64", -64),
+		otlog.Uint32("uint32", 32),
+		otlog.Uint64("uint64", math.MaxUint64),
+		otlog.Float32("float32", 3.5),
+		otlog.Float64("float64", 6.25),
+		otlog.Object("object", uint64(7)),
+		otlog.Object("struct", struct{ A int }{1}),
+		otlog.Lazy(func(fv otlog.Encoder) {
+			fv.EmitUint64("lazy", 8)
+		}),
+	)
+	span.Finish()
+
+	want := map[label.Key]label.Value{
+		"string":  label.StringValue("value"),
+		"bool":    label.BoolValue(true),
+		"int":     label.IntValue(-1),
+		"int32":   label.Int32Value(-32),
+		"int64":   label.Int64Value(-64),
+		"uint32":  label.Uint32Value(32),
+		"uint64":  label.Uint64Value(math.MaxUint64),
+		"float32": label.Float32Value(3.5),
+		"float64": label.Float64Value(6.25),
+		"object":  label.Uint64Value(7),
+		"struct":  label.StringValue(`{"A":1}`),
+		"lazy":    label.Uint64Value(8),
+	}
+	events := sr.Completed()[0].Events()
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	got := events[0].Attributes
+	for k, v := range want {
+		if got[k].Type() != v.Type() || got[k] != v {
+			t.Errorf("got %s field %s(%s), want %s(%s)", k, got[k].Type(), got[k].Emit(), v.Type(), v.Emit())
+		}
+	}
+}
+
+// multiContextPropagator extracts a current span and a remote span
+// context at the same time.
+type multiContextPropagator struct {
+	current, remote otel.SpanContext
+}
+
+func (p multiContextPropagator) Inject(context.Context, otel.TextMapCarrier) {}
+
+func (p multiContextPropagator) Extract(ctx context.Context, _ otel.TextMapCarrier) context.Context {
+	ctx = otel.ContextWithRemoteSpanContext(ctx, p.remote)
+	return otel.ContextWithSpan(ctx, fakeSpan{Span: noop.Span, sc: p.current})
+}
+
+func (p multiContextPropagator) Fields() []string { return nil }
+
+func TestExtractMultipleContexts(t *testing.T) {
+	prop := multiContextPropagator{
+		current: otel.SpanContext{TraceID: otel.TraceID{1}, SpanID: otel.SpanID{1}},
+		remote:  otel.SpanContext{TraceID: otel.TraceID{2}, SpanID: otel.SpanID{2}, TraceFlags: otel.FlagsSampled},
+	}
+	bt, sr := newTestBridgeTracer()
+	bt.SetTextMapPropagator(prop)
+
+	sc, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(http.Header{}))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+	if got := sc.(*bridgeSpanContext).otelSpanContext; got != prop.current {
+		t.Errorf("got extracted span context %v, want %v", got, prop.current)
+	}
+	links := ExtractedLinks(sc)
+	if len(links) != 1 || links[0].SpanContext != prop.remote {
+		t.Fatalf("got extracted links %v, want a link to %v", links, prop.remote)
+	}
+	if ExtractedLinks(ot.NoopTracer{}.StartSpan("foreign").Context()) != nil {
+		t.Error("got extracted links for a foreign span context")
+	}
+
+	span := bt.StartSpan("test", ot.ChildOf(sc))
+	span.Finish()
+	got := sr.Completed()[0]
+	if !got.IsChildOf(prop.current) {
+		t.Error("span is not a child of the extracted span context")
+	}
+	spanLinks := got.Links()
+	if _, ok := spanLinks[prop.remote]; !ok || len(spanLinks) != 1 {
+		t.Errorf("got span links %v, want a link to %v", spanLinks, prop.remote)
+	}
+}
+
+func TestStatusCodeTag(t *testing.T) {
+	testCases := []struct {
+		name  string
+		start ot.Tags
+		apply func(ot.Span)
+		want  codes.Code
+	}{
+		{
+			name:  "ok tag",
+			apply: func(s ot.Span) { s.SetTag(StatusCodeTagKey, "OK") },
+			want:  codes.Ok,
+		},
+		{
+			name:  "ok start tag",
+			start: ot.Tags{StatusCodeTagKey: "ok"},
+			want:  codes.Ok,
+		},
+		{
+			name: "ok tag after error tag",
+			apply: func(s ot.Span) {
+				s.SetTag(string(otext.Error), true)
+				s.SetTag(StatusCodeTagKey, "ok")
+			},
+			want: codes.Error,
+		},
+		{
+			name:  "ok start tag with error start tag",
+			start: ot.Tags{StatusCodeTagKey: "ok", string(otext.Error): true},
+			want:  codes.Error,
+		},
+		{
+			name: "error tag after ok tag",
+			apply: func(s ot.Span) {
+				s.SetTag(StatusCodeTagKey, "ok")
+				s.SetTag(string(otext.Error), true)
+			},
+			want: codes.Error,
+		},
+		{
+			name: "forced ok after error tag",
+			apply: func(s ot.Span) {
+				s.SetTag(string(otext.Error), true)
+				if !SetStatusOK(s) {
+					t.Error("SetStatusOK failed for a bridge span")
+				}
+			},
+			want: codes.Ok,
+		},
+		{
+			name:  "error tag",
+			apply: func(s ot.Span) { s.SetTag(StatusCodeTagKey, "Error") },
+			want:  codes.Error,
+		},
+		{
+			name:  "error start tag",
+			start: ot.Tags{StatusCodeTagKey: "error"},
+			want:  codes.Error,
+		},
+		{
+			name: "unset tag after error tag",
+			apply: func(s ot.Span) {
+				s.SetTag(string(otext.Error), true)
+				s.SetTag(StatusCodeTagKey, "unset")
+			},
+			want: codes.Unset,
+		},
+		{
+			name:  "unset start tag with error start tag",
+			start: ot.Tags{StatusCodeTagKey: "UNSET", string(otext.Error): true},
+			want:  codes.Unset,
+		},
+		{
+			name: "unset tag after ok tag",
+			apply: func(s ot.Span) {
+				s.SetTag(StatusCodeTagKey, "ok")
+				s.SetTag(StatusCodeTagKey, "unset")
+			},
+			want: codes.Unset,
+		},
+		{
+			name:  "typed ok tag",
+			apply: func(s ot.Span) { s.SetTag(StatusCodeTagKey, codes.Ok) },
+			want:  codes.Ok,
+		},
+		{
+			name:  "typed error start tag",
+			start: ot.Tags{StatusCodeTagKey: codes.Error},
+			want:  codes.Error,
+		},
+		{
+			name: "typed unset tag after error tag",
+			apply: func(s ot.Span) {
+				s.SetTag(StatusCodeTagKey, codes.Error)
+				s.SetTag(StatusCodeTagKey, codes.Unset)
+			},
+			want: codes.Unset,
+		},
+		{
+			name: "unknown value",
+			apply: func(s ot.Span) {
+				s.SetTag(StatusCodeTagKey, "ok")
+				s.SetTag(StatusCodeTagKey, "failed")
+			},
+			want: codes.Ok,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bt, sr := newTestBridgeTracer()
+			span := bt.StartSpan("test", tc.start)
+			if tc.apply != nil {
+				tc.apply(span)
+			}
+			span.Finish()
+
+			got := sr.Completed()[0]
+			if got.StatusCode() != tc.want {
+				t.Errorf("got status %v, want %v", got.StatusCode(), tc.want)
+			}
+			if _, ok := got.Attributes()[StatusCodeTagKey]; ok {
+				t.Error("status code tag recorded as an attribute")
+			}
+		})
+	}
+
+	if SetStatusOK(ot.NoopTracer{}.StartSpan("foreign")) {
+		t.Error("SetStatusOK succeeded for a foreign span")
+	}
+}
+
+func TestErrorStatusDescription(t *testing.T) {
+	errorTag := string(otext.Error)
+	testCases := []struct {
+		name  string
+		start ot.Tags
+		apply func(ot.Span)
+		want  string
+	}{
+		{
+			name:  "no description",
+			start: ot.Tags{errorTag: true},
+			want:  "",
+		},
+		{
+			name:  "message start tag",
+			start: ot.Tags{errorTag: true, "message": "failed"},
+			want:  "failed",
+		},
+		{
+			name:  "error.message start tag over message start tag",
+			start: ot.Tags{errorTag: true, "message": "failed", "error.message": "timeout"},
+			want:  "timeout",
+		},
+		{
+			name: "message before error",
+			apply: func(s ot.Span) {
+				s.SetTag("message", "failed")
+				s.SetTag(errorTag, true)
+			},
+			want: "failed",
+		},
+		{
+			name: "message after error",
+			apply: func(s ot.Span) {
+				s.SetTag(errorTag, true)
+				s.SetTag("message", "failed")
+			},
+			want: "failed",
+		},
+		{
+			name: "error.message before error and message after",
+			apply: func(s ot.Span) {
+				s.SetTag("error.message", "timeout")
+				s.SetTag(errorTag, true)
+				s.SetTag("message", "failed")
+			},
+			want: "timeout",
+		},
+		{
+			name: "message before error and error.message after",
+			apply: func(s ot.Span) {
+				s.SetTag("message", "failed")
+				s.SetTag(errorTag, true)
+				s.SetTag("error.message", "timeout")
+			},
+			want: "timeout",
+		},
+		{
+			name: "error status tag",
+			apply: func(s ot.Span) {
+				s.SetTag("error.message", "timeout")
+				s.SetTag(StatusCodeTagKey, "error")
+			},
+			want: "timeout",
+		},
+		{
+			name:  "message start tag and error tag",
+			start: ot.Tags{"message": "failed"},
+			apply: func(s ot.Span) { s.SetTag(errorTag, true) },
+			want:  "failed",
+		},
+		{
+			name:  "status description start tag with status code start tag",
+			start: ot.Tags{StatusCodeTagKey: "error", StatusDescriptionTagKey: "quota exceeded"},
+			want:  "quota exceeded",
+		},
+		{
+			name:  "status description start tag over error.message start tag",
+			start: ot.Tags{errorTag: true, "error.message": "timeout", StatusDescriptionTagKey: "quota exceeded"},
+			want:  "quota exceeded",
+		},
+		{
+			name: "status description after error status tag",
+			apply: func(s ot.Span) {
+				s.SetTag(StatusCodeTagKey, "error")
+				s.SetTag("error.message", "timeout")
+				s.SetTag(StatusDescriptionTagKey, "quota exceeded")
+			},
+			want: "quota exceeded",
+		},
+		{
+			name: "error.message after status description",
+			apply: func(s ot.Span) {
+				s.SetTag(StatusDescriptionTagKey, "quota exceeded")
+				s.SetTag(errorTag, true)
+				s.SetTag("error.message", "timeout")
+			},
+			want: "quota exceeded",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bt, sr := newTestBridgeTracer()
+			span := bt.StartSpan("test", tc.start)
+			if tc.apply != nil {
+				tc.apply(span)
+			}
+			span.Finish()
+
+			got := sr.Completed()[0]
+			if got.StatusCode() != codes.Error {
+				t.Errorf("got status %v, want %v", got.StatusCode(), codes.Error)
+			}
+			if got.StatusMessage() != tc.want {
+				t.Errorf("got status description %q, want %q", got.StatusMessage(), tc.want)
+			}
+			if _, ok := got.Attributes()[StatusDescriptionTagKey]; ok {
+				t.Error("status description tag recorded as an attribute")
+			}
+		})
+	}
+
+	t.Run("message without error", func(t *testing.T) {
+		bt, sr := newTestBridgeTracer()
+		span := bt.StartSpan("test")
+		span.SetTag("message", "done")
+		span.Finish()
+
+		if got := sr.Completed()[0]; got.StatusCode() != codes.Unset || got.StatusMessage() != "" {
+			t.Errorf("got status %v %q, want it unset", got.StatusCode(), got.StatusMessage())
+		}
+	})
+
+	t.Run("message after forced ok", func(t *testing.T) {
+		bt, sr := newTestBridgeTracer()
+		span := bt.StartSpan("test", ot.Tags{errorTag: true})
+		SetStatusOK(span)
+		span.SetTag("message", "recovered")
+		span.Finish()
+
+		if got := sr.Completed()[0]; got.StatusCode() != codes.Ok || got.StatusMessage() != "" {
+			t.Errorf("got status %v %q, want %v without a description", got.StatusCode(), got.StatusMessage(), codes.Ok)
+		}
+	})
+}
+
+func TestSpanKindTag(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opt  ot.StartSpanOption
+		want otel.SpanKind
+	}{
+		{name: "client enum", opt: otext.SpanKindRPCClient, want: otel.SpanKindClient},
+		{name: "server enum", opt: otext.SpanKindRPCServer, want: otel.SpanKindServer},
+		{name: "producer enum", opt: ot.Tag{Key: string(otext.SpanKind), Value: otext.SpanKindProducerEnum}, want: otel.SpanKindProducer},
+		{name: "consumer enum", opt: ot.Tag{Key: string(otext.SpanKind), Value: otext.SpanKindConsumerEnum}, want: otel.SpanKindConsumer},
+		{name: "string", opt: ot.Tag{Key: string(otext.SpanKind), Value: "Server"}, want: otel.SpanKindServer},
+		{name: "unknown", opt: ot.Tag{Key: string(otext.SpanKind), Value: 42}, want: otel.SpanKindInternal},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			bt, sr := newTestBridgeTracer()
+			bt.StartSpan("test", tc.opt).Finish()
+			if got := sr.Completed()[0].SpanKind(); got != tc.want {
+				t.Errorf("got span kind %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHTTPStatusToSpanStatus(t *testing.T) {
+	testCases := []struct {
+		kind   otext.SpanKindEnum
+		status uint16
+		want   codes.Code
+	}{
+		{kind: otext.SpanKindRPCServerEnum, status: 200, want: codes.Unset},
+		{kind: otext.SpanKindRPCServerEnum, status: 404, want: codes.Unset},
+		{kind: otext.SpanKindRPCServerEnum, status: 500, want: codes.Error},
+		{kind: otext.SpanKindRPCClientEnum, status: 200, want: codes.Unset},
+		{kind: otext.SpanKindRPCClientEnum, status: 404, want: codes.Error},
+		{kind: otext.SpanKindRPCClientEnum, status: 500, want: codes.Error},
+		{kind: "", status: 200, want: codes.Unset},
+		{kind: "", status: 404, want: codes.Unset},
+		{kind: "", status: 500, want: codes.Error},
+	}
+
+	for _, tc := range testCases {
+		tags := ot.Tags{}
+		if tc.kind != "" {
+			tags[string(otext.SpanKind)] = tc.kind
+		}
+		name := fmt.Sprintf("%q kind with status %d", tc.kind, tc.status)
+
+		t.Run(name+" set on start", func(t *testing.T) {
+			bt, sr := newTestBridgeTracer(WithHTTPStatusToSpanStatus())
+			startTags := ot.Tags{string(otext.HTTPStatusCode): tc.status}
+			for k, v := range tags {
+				startTags[k] = v
+			}
+			bt.StartSpan("test", startTags).Finish()
+
+			got := sr.Completed()[0]
+			if got.StatusCode() != tc.want {
+				t.Errorf("got status %v, want %v", got.StatusCode(), tc.want)
+			}
+		})
+
+		t.Run(name+" set later", func(t *testing.T) {
+			bt, sr := newTestBridgeTracer(WithHTTPStatusToSpanStatus())
+			span := bt.StartSpan("test", tags)
+			otext.HTTPStatusCode.Set(span, tc.status)
+			span.Finish()
+
+			got := sr.Completed()[0]
+			if got.StatusCode() != tc.want {
+				t.Errorf("got status %v, want %v", got.StatusCode(), tc.want)
+			}
+			if _, ok := got.Attributes()[label.Key(otext.HTTPStatusCode)]; !ok {
+				t.Error("HTTP status code tag not recorded as an attribute")
+			}
+		})
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		bt, sr := newTestBridgeTracer()
+		span := bt.StartSpan("test", otext.SpanKindRPCClient)
+		otext.HTTPStatusCode.Set(span, 500)
+		span.Finish()
+
+		if got := sr.Completed()[0].StatusCode(); got != codes.Unset {
+			t.Errorf("got status %v, want %v", got, codes.Unset)
+		}
+	})
+}
+
+func TestPendingBaggageItems(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	span := bt.StartSpan("test")
+	if got := PendingBaggageItems(span); len(got) != 0 {
+		t.Errorf("got pending baggage %v for a new span", got)
+	}
+
+	span.SetBaggageItem("key1", "value1")
+	span.SetBaggageItem("key2", "value2")
+	got := PendingBaggageItems(span)
+	want := map[string]string{"key1": "value1", "key2": "value2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got pending baggage %v, want %v", got, want)
+	}
+
+	got["key3"] = "value3"
+	if _, ok := PendingBaggageItems(span)["key3"]; ok {
+		t.Error("modifying the returned baggage changed the span")
+	}
+
+	if PendingBaggageItems(ot.NoopTracer{}.StartSpan("foreign")) != nil {
+		t.Error("got pending baggage for a foreign span")
+	}
+}
+
+func TestPendingBaggageItemsConcurrently(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	span := bt.StartSpan("test")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			span.SetBaggageItem(fmt.Sprintf("key%d", i), "value")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		PendingBaggageItems(span)
+	}
+	<-done
+	if got := len(PendingBaggageItems(span)); got != 100 {
+		t.Errorf("got %d pending baggage items, want 100", got)
+	}
+}
+
+func TestAddLinkEvent(t *testing.T) {
+	bt, sr := newTestBridgeTracer()
+	cause := bt.StartSpan("cause")
+	cause.Finish()
+	span := bt.StartSpan("effect")
+	if !AddLinkEvent(span, cause.Context(), "retry of") {
+		t.Fatal("AddLinkEvent failed for a bridge span")
+	}
+	span.Finish()
+
+	causeSC := cause.Context().(*bridgeSpanContext).otelSpanContext
+	events := sr.Completed()[1].Events()
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if events[0].Name != linkEventName {
+		t.Errorf("got event name %q, want %q", events[0].Name, linkEventName)
+	}
+	want := map[label.Key]label.Value{
+		linkEventTraceIDKey: label.StringValue(causeSC.TraceID.String()),
+		linkEventSpanIDKey:  label.StringValue(causeSC.SpanID.String()),
+		linkEventReasonKey:  label.StringValue("retry of"),
+	}
+	if !reflect.DeepEqual(events[0].Attributes, want) {
+		t.Errorf("got event attributes %v, want %v", events[0].Attributes, want)
+	}
+
+	foreign := ot.NoopTracer{}.StartSpan("foreign")
+	if AddLinkEvent(foreign, cause.Context(), "") {
+		t.Error("AddLinkEvent succeeded for a foreign span")
+	}
+	if AddLinkEvent(span, foreign.Context(), "") {
+		t.Error("AddLinkEvent succeeded for a foreign span context")
+	}
+}
+
+// nonDeferringTracer claims to support the deferred context setup, but
+// sets up the context anyway.
+type nonDeferringTracer struct {
+	otel.Tracer
+}
+
+var _ migration.DeferredContextSetupTracerExtension = nonDeferringTracer{}
+
+func (nonDeferringTracer) DeferredContextSetupHook(ctx context.Context, span otel.Span) context.Context {
+	return otel.ContextWithSpan(ctx, span)
+}
+
+func TestDeferredSetupWarning(t *testing.T) {
+	testCases := []struct {
+		name   string
+		tracer otel.Tracer
+		warn   bool
+	}{
+		{
+			name:   "tracer without extension",
+			tracer: oteltest.NewTracerProvider().Tracer(""),
+			warn:   false,
+		},
+		{
+			name:   "deferring tracer",
+			tracer: internal.NewMockTracer(),
+			warn:   false,
+		},
+		{
+			name:   "non-deferring tracer with extension",
+			tracer: nonDeferringTracer{oteltest.NewTracerProvider().Tracer("")},
+			warn:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var warnings []string
+			bt := NewBridgeTracer()
+			bt.SetWarningHandler(func(msg string) { warnings = append(warnings, msg) })
+			bt.SetOpenTelemetryTracer(tc.tracer)
+			bt.StartSpan("test").Finish()
+
+			if got := len(warnings) > 0; got != tc.warn {
+				t.Errorf("got warnings %q, want warning: %v", warnings, tc.warn)
+			}
+		})
+	}
+}
+
+func TestExtractWithContext(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	callerCtx := baggage.ContextWithMap(context.Background(), oteltest.NewOrderedBaggage(
+		label.String("caller", "1"),
+		label.String("shared", "caller"),
+	).Map())
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	header.Set("otcorrelations", "shared=extracted,extracted=2")
+	sc, err := bt.ExtractWithContext(callerCtx, ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+	want := map[string]string{
+		"caller":    "1",
+		"shared":    "extracted",
+		"extracted": "2",
+	}
+	if got := baggageItems(sc); !reflect.DeepEqual(got, want) {
+		t.Errorf("got baggage %v, want %v", got, want)
+	}
+
+	sc, err = bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+	want = map[string]string{
+		"shared":    "extracted",
+		"extracted": "2",
+	}
+	if got := baggageItems(sc); !reflect.DeepEqual(got, want) {
+		t.Errorf("got baggage %v without caller context, want %v", got, want)
+	}
+
+	tracer := oteltest.NewTracerProvider().Tracer("")
+	activeCtx, _ := tracer.Start(callerCtx, "active")
+	_, err = bt.ExtractWithContext(activeCtx, ot.HTTPHeaders, ot.HTTPHeadersCarrier(http.Header{}))
+	if err != ot.ErrSpanContextNotFound {
+		t.Errorf("got error %v for a caller context with an active span, want %v", err, ot.ErrSpanContextNotFound)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	previous := ot.GlobalTracer()
+	previousProvider := otelglobal.TracerProvider()
+	previousPropagator := otelglobal.TextMapPropagator()
+	// Install cannot be undone, but the other tests expect the
+	// globals they found.
+	defer func() {
+		ot.SetGlobalTracer(previous)
+		otelglobal.SetTracerProvider(previousProvider)
+		otelglobal.SetTextMapPropagator(previousPropagator)
+	}()
+	sr := new(oteltest.StandardSpanRecorder)
+	tracer := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)).Tracer("")
+	prop := propagators.Baggage{}
+
+	bt := Install(tracer, prop)
+	if ot.GlobalTracer() != bt {
+		t.Errorf("got global tracer %T, want the installed bridge tracer", ot.GlobalTracer())
+	}
+	if _, ok := otelglobal.TracerProvider().(*WrapperTracerProvider); !ok {
+		t.Errorf("got global tracer provider %T, want %T", otelglobal.TracerProvider(), &WrapperTracerProvider{})
+	}
+	if got := bt.getPropagator(); got != prop {
+		t.Errorf("got bridge propagator %T, want %T", got, prop)
+	}
+	if got := otelglobal.TextMapPropagator(); got != prop {
+		t.Errorf("got global propagator %T, want %T", got, prop)
+	}
+
+	ot.StartSpan("test").Finish()
+	_, span := otelglobal.Tracer("").Start(context.Background(), "otel test")
+	span.End()
+	if got := len(sr.Completed()); got != 2 {
+		t.Errorf("got %d spans recorded through the global tracers, want 2", got)
+	}
+}
+
+func TestSemanticConventionTags(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []BridgeOption
+		want map[label.Key]label.Value
+	}{
+		{
+			name: "disabled",
+			want: map[label.Key]label.Value{
+				"peer.hostname": label.StringValue("example.com"),
+				"http.method":   label.StringValue("GET"),
+				"db.type":       label.StringValue("sql"),
+			},
+		},
+		{
+			name: "replace original tags",
+			opts: []BridgeOption{WithSemanticConventionTags()},
+			want: map[label.Key]label.Value{
+				semconv.NetPeerNameKey: label.StringValue("example.com"),
+				"http.method":          label.StringValue("GET"),
+				semconv.DBSystemKey:    label.StringValue("sql"),
+			},
+		},
+		{
+			name: "keep original tags",
+			opts: []BridgeOption{WithSemanticConventionTags(), WithKeepOriginalTags()},
+			want: map[label.Key]label.Value{
+				semconv.NetPeerNameKey: label.StringValue("example.com"),
+				"peer.hostname":        label.StringValue("example.com"),
+				"http.method":          label.StringValue("GET"),
+				semconv.DBSystemKey:    label.StringValue("sql"),
+				"db.type":              label.StringValue("sql"),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bt, sr := newTestBridgeTracer(tc.opts...)
+			span := bt.StartSpan("test", ot.Tags{
+				string(otext.PeerHostname): "example.com",
+				string(otext.HTTPMethod):   "GET",
+			})
+			otext.DBType.Set(span, "sql")
+			span.Finish()
+
+			if got := sr.Completed()[0].Attributes(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got attributes %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSemanticConventionTagsCase(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []BridgeOption
+		want map[label.Key]label.Value
+	}{
+		{
+			name: "case-insensitive",
+			opts: []BridgeOption{WithSemanticConventionTags()},
+			want: map[label.Key]label.Value{
+				semconv.NetPeerNameKey: label.StringValue("example.com"),
+				semconv.DBNameKey:      label.StringValue("users"),
+			},
+		},
+		{
+			name: "case-insensitive keeping original tags",
+			opts: []BridgeOption{WithSemanticConventionTags(), WithKeepOriginalTags()},
+			want: map[label.Key]label.Value{
+				semconv.NetPeerNameKey: label.StringValue("example.com"),
+				"PEER.HOSTNAME":        label.StringValue("example.com"),
+				semconv.DBNameKey:      label.StringValue("users"),
+				"Db.Instance":          label.StringValue("users"),
+			},
+		},
+		{
+			name: "case-sensitive",
+			opts: []BridgeOption{WithSemanticConventionTags(), WithCaseSensitiveTagMapping()},
+			want: map[label.Key]label.Value{
+				"PEER.HOSTNAME": label.StringValue("example.com"),
+				"Db.Instance":   label.StringValue("users"),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bt, sr := newTestBridgeTracer(tc.opts...)
+			span := bt.StartSpan("test", ot.Tags{"PEER.HOSTNAME": "example.com"})
+			span.SetTag("Db.Instance", "users")
+			span.Finish()
+
+			if got := sr.Completed()[0].Attributes(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got attributes %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBaggageDebugEvents(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []BridgeOption
+		want []map[label.Key]label.Value
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name: "redacted by default",
+			opts: []BridgeOption{WithBaggageDebugEvents(), WithMaxBaggageSize(8)},
+			want: []map[label.Key]label.Value{
+				{
+					baggageEventKeyKey:      label.StringValue("user"),
+					baggageEventValueKey:    label.StringValue("[redacted]"),
+					baggageEventAcceptedKey: label.BoolValue(true),
+				},
+				{
+					baggageEventKeyKey:      label.StringValue("tenant"),
+					baggageEventValueKey:    label.StringValue("[redacted]"),
+					baggageEventAcceptedKey: label.BoolValue(false),
+				},
+			},
+		},
+		{
+			name: "custom redactor",
+			opts: []BridgeOption{
+				WithBaggageDebugEvents(),
+				WithMaxBaggageSize(8),
+				WithBaggageValueRedactor(func(key, value string) string { return key + "=" + value }),
+			},
+			want: []map[label.Key]label.Value{
+				{
+					baggageEventKeyKey:      label.StringValue("user"),
+					baggageEventValueKey:    label.StringValue("user=bob"),
+					baggageEventAcceptedKey: label.BoolValue(true),
+				},
+				{
+					baggageEventKeyKey:      label.StringValue("tenant"),
+					baggageEventValueKey:    label.StringValue("tenant=acme"),
+					baggageEventAcceptedKey: label.BoolValue(false),
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bt, sr := newTestBridgeTracer(tc.opts...)
+			span := bt.StartSpan("test")
+			span.SetBaggageItem("user", "bob")
+			span.SetBaggageItem("tenant", "acme")
+			span.Finish()
+
+			var got []map[label.Key]label.Value
+			for _, e := range sr.Completed()[0].Events() {
+				if e.Name == baggageEventName {
+					got = append(got, e.Attributes)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got baggage events %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResetWarnings(t *testing.T) {
+	var warnings []string
+	bt := NewBridgeTracer()
+	bt.SetWarningHandler(func(msg string) { warnings = append(warnings, msg) })
+
+	// The no-op tracer used until a tracer is set does not defer the
+	// context setup, so only the unset tracer warning is emitted.
+	bt.StartSpan("test").Finish()
+	if len(warnings) != 1 {
+		t.Fatalf("got warnings %q, want one warning about the unset tracer", warnings)
+	}
+	bt.StartSpan("test").Finish()
+	if len(warnings) != 1 {
+		t.Fatalf("got warnings %q, want the warning suppressed", warnings)
+	}
+	bt.resetWarnings()
+	bt.StartSpan("test").Finish()
+	if len(warnings) != 2 {
+		t.Fatalf("got warnings %q, want the warning emitted again after reset", warnings)
+	}
+
+	bt.SetOpenTelemetryTracer(nonDeferringTracer{oteltest.NewTracerProvider().Tracer("")})
+	bt.StartSpan("test").Finish()
+	bt.StartSpan("test").Finish()
+	if len(warnings) != 3 {
+		t.Fatalf("got warnings %q, want one warning about the deferred setup", warnings)
+	}
+	bt.resetWarnings()
+	bt.StartSpan("test").Finish()
+	if len(warnings) != 4 {
+		t.Fatalf("got warnings %q, want the deferred setup warning emitted again after reset", warnings)
+	}
+}
+
+func TestBaggageItemWithProperties(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	span := bt.StartSpan("test")
+	entry := BaggageEntry{
+		Value: "bob",
+		Properties: []BaggageProperty{
+			{Key: "ttl", Value: "60"},
+			{Key: "internal"},
+		},
+	}
+	if !SetBaggageItemWithProperties(span, "user", entry) {
+		t.Fatal("SetBaggageItemWithProperties failed for a bridge span")
+	}
+	span.SetBaggageItem("plain", "value")
+
+	if got := span.BaggageItem("user"); got != "bob" {
+		t.Errorf("got baggage item %q, want the plain value %q", got, "bob")
+	}
+	want := map[string]string{"User": "bob", "Plain": "value"}
+	if got := baggageItems(span.Context()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got baggage %v, want %v", got, want)
+	}
+
+	child := bt.StartSpan("child", ot.ChildOf(span.Context()))
+	header := http.Header{}
+	if err := bt.Inject(child.Context(), ot.HTTPHeaders, ot.HTTPHeadersCarrier(header)); err != nil {
+		t.Fatalf("failed to inject the span context: %v", err)
+	}
+	members := strings.Split(header.Get("otcorrelations"), ",")
+	sort.Strings(members)
+	if want := []string{"Plain=value", "User=bob;ttl=60;internal"}; !reflect.DeepEqual(members, want) {
+		t.Errorf("got baggage members %q, want %q", members, want)
+	}
+
+	extracted, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+	if got, ok := BaggageItemEntry(extracted, "user"); !ok || !reflect.DeepEqual(got, entry) {
+		t.Errorf("got extracted baggage entry %v, %v, want %v", got, ok, entry)
+	}
+	if got, ok := BaggageItemEntry(extracted, "plain"); !ok || !reflect.DeepEqual(got, BaggageEntry{Value: "value"}) {
+		t.Errorf("got extracted baggage entry %v, %v without properties", got, ok)
+	}
+	if got := baggageItems(extracted); !reflect.DeepEqual(got, want) {
+		t.Errorf("got extracted baggage %v, want %v", got, want)
+	}
+
+	span.SetBaggageItem("user", "alice")
+	if got, _ := BaggageItemEntry(span.Context(), "user"); len(got.Properties) != 0 {
+		t.Errorf("got properties %v after a plain update, want none", got.Properties)
+	}
+	if SetBaggageItemWithProperties(ot.NoopTracer{}.StartSpan("foreign"), "user", entry) {
+		t.Error("SetBaggageItemWithProperties succeeded for a foreign span")
+	}
+}
+
+func TestLogEventName(t *testing.T) {
+	testCases := []struct {
+		name   string
+		fields []otlog.Field
+		want   string
+	}{
+		{
+			name:   "event field",
+			fields: []otlog.Field{otlog.String("message", "retrying"), otlog.String("event", "retry")},
+			want:   "retry",
+		},
+		{
+			name:   "message field",
+			fields: []otlog.Field{otlog.String("message", "retrying"), otlog.Int("attempt", 2)},
+			want:   "retrying",
+		},
+		{
+			name:   "no naming field",
+			fields: []otlog.Field{otlog.Int("attempt", 2)},
+			want:   "log",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bt, sr := newTestBridgeTracer()
+			span := bt.StartSpan("test")
+			span.LogFields(tc.fields...)
+			span.FinishWithOptions(ot.FinishOptions{
+				LogRecords: []ot.LogRecord{{Fields: tc.fields}},
+			})
+
+			events := sr.Completed()[0].Events()
+			if len(events) != 2 {
+				t.Fatalf("got %d events, want 2", len(events))
+			}
+			for _, e := range events {
+				if e.Name != tc.want {
+					t.Errorf("got event name %q, want %q", e.Name, tc.want)
+				}
+				if len(e.Attributes) != len(tc.fields) {
+					t.Errorf("got attributes %v, want all %d fields", e.Attributes, len(tc.fields))
+				}
+			}
+		})
+	}
+}
+
+func TestNewRemoteSpanContext(t *testing.T) {
+	bt, sr := newTestBridgeTracer()
+	bt.SetTextMapPropagator(propagators.TraceContext{})
+
+	sc, err := NewRemoteSpanContext("4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true)
+	if err != nil {
+		t.Fatalf("failed to create the span context: %v", err)
+	}
+	header := http.Header{}
+	if err := bt.Inject(sc, ot.HTTPHeaders, ot.HTTPHeadersCarrier(header)); err != nil {
+		t.Fatalf("failed to inject the span context: %v", err)
+	}
+	if got, want := header.Get("traceparent"), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"; got != want {
+		t.Errorf("got traceparent %q, want %q", got, want)
+	}
+
+	bt.StartSpan("child", ot.ChildOf(sc)).Finish()
+	if got := sr.Completed()[0].ParentSpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("got parent span ID %s, want the remote span ID", got)
+	}
+
+	for _, ids := range [][2]string{
+		{"not-hex", "00f067aa0ba902b7"},
+		{"4bf92f3577b34da6a3ce929d0e0e4736", "0000000000000000"},
+		{"00000000000000000000000000000000", "00f067aa0ba902b7"},
+	} {
+		if _, err := NewRemoteSpanContext(ids[0], ids[1], false); err == nil {
+			t.Errorf("got no error for trace ID %q and span ID %q", ids[0], ids[1])
+		}
+	}
+}
+
+func TestTracestateRoundTrip(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	bt.SetTextMapPropagator(propagators.TraceContext{})
+
+	in := http.Header{}
+	in.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	in.Set("tracestate", "vendor=opaque")
+	remote, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(in))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+
+	span := bt.StartSpan("child", ot.ChildOf(remote))
+	grandchild := bt.StartSpan("grandchild", ot.ChildOf(span.Context()))
+	out := http.Header{}
+	if err := bt.Inject(grandchild.Context(), ot.HTTPHeaders, ot.HTTPHeadersCarrier(out)); err != nil {
+		t.Fatalf("failed to inject the span context: %v", err)
+	}
+
+	if got := out.Get("tracestate"); got != "vendor=opaque" {
+		t.Errorf("got tracestate %q, want the extracted one", got)
+	}
+	sc := grandchild.Context().(*bridgeSpanContext).otelSpanContext
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + sc.SpanID.String() + "-01"
+	if got := out.Get("traceparent"); got != want {
+		t.Errorf("got traceparent %q, want %q", got, want)
+	}
+
+	out = http.Header{}
+	unrelated := bt.StartSpan("unrelated")
+	if err := bt.Inject(unrelated.Context(), ot.HTTPHeaders, ot.HTTPHeadersCarrier(out)); err != nil {
+		t.Fatalf("failed to inject the span context: %v", err)
+	}
+	if got := out.Get("tracestate"); got != "" {
+		t.Errorf("got tracestate %q for an unrelated span, want none", got)
+	}
+}
+
+// injectContextRecorder records the context passed to Inject.
+type injectContextRecorder struct {
+	ctx context.Context
+}
+
+func (r *injectContextRecorder) Inject(ctx context.Context, _ otel.TextMapCarrier) { r.ctx = ctx }
+
+func (r *injectContextRecorder) Extract(ctx context.Context, _ otel.TextMapCarrier) context.Context {
+	return ctx
+}
+
+func (r *injectContextRecorder) Fields() []string { return nil }
+
+func TestInjectDoesNotReplayExtractionContext(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	recorder := &injectContextRecorder{}
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, recorder))
+
+	in := http.Header{}
+	in.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	in.Set("tracestate", "vendor=opaque")
+	callerCtx, cancel := context.WithCancel(context.WithValue(context.Background(), tenantKey, "acme"))
+	defer cancel()
+	_, remote, err := bt.ExtractContext(callerCtx, ot.HTTPHeaders, ot.HTTPHeadersCarrier(in))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+
+	span := bt.StartSpan("child", ot.ChildOf(remote))
+	out := http.Header{}
+	if err := bt.Inject(span.Context(), ot.HTTPHeaders, ot.HTTPHeadersCarrier(out)); err != nil {
+		t.Fatalf("failed to inject the span context: %v", err)
+	}
+	if got := out.Get("tracestate"); got != "vendor=opaque" {
+		t.Errorf("got tracestate %q, want the extracted one", got)
+	}
+	if got := recorder.ctx.Value(tenantKey); got != nil {
+		t.Errorf("got value %v of the extraction context in Inject, want none", got)
+	}
+	if recorder.ctx.Done() != nil {
+		t.Error("got the cancellation of the extraction context in Inject, want none")
+	}
+}
+
+func TestStrictInjectValidation(t *testing.T) {
+	testCases := []struct {
+		name      string
+		opts      []BridgeOption
+		wantErr   error
+		wantPanic bool
+	}{
+		{
+			name:    "default",
+			wantErr: ot.ErrInvalidSpanContext,
+		},
+		{
+			name:    "return error",
+			opts:    []BridgeOption{WithStrictInjectValidation(InjectValidationReturnError)},
+			wantErr: ot.ErrInvalidSpanContext,
+		},
+		{
+			name: "silent no-op",
+			opts: []BridgeOption{WithStrictInjectValidation(InjectValidationSilentNoop)},
+		},
+		{
+			name:      "panic",
+			opts:      []BridgeOption{WithStrictInjectValidation(InjectValidationPanic)},
+			wantPanic: true,
+		},
+	}
+
+	invalid := map[string]ot.SpanContext{
+		"foreign": ot.NoopTracer{}.StartSpan("foreign").Context(),
+		"invalid": newBridgeSpanContext(otel.SpanContext{}, nil, config{}),
+	}
+
+	for _, tc := range testCases {
+		for scName, sc := range invalid {
+			t.Run(tc.name+" "+scName, func(t *testing.T) {
+				bt, _ := newTestBridgeTracer(tc.opts...)
+				header := http.Header{}
+				defer func() {
+					if r := recover(); (r != nil) != tc.wantPanic {
+						t.Errorf("got panic %v, want panic: %v", r, tc.wantPanic)
+					}
+				}()
+				err := bt.Inject(sc, ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+				if err != tc.wantErr {
+					t.Errorf("got error %v, want %v", err, tc.wantErr)
+				}
+				if len(header) != 0 {
+					t.Errorf("got injected headers %v, want none", header)
+				}
+			})
+		}
+	}
+}
+
+func TestBaggageHeader(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	span := bt.StartSpan("test")
+	span.SetBaggageItem("user", "bob")
+	header, err := bt.BaggageHeader(span.Context())
+	if err != nil {
+		t.Fatalf("failed to get the baggage header: %v", err)
+	}
+	want := http.Header{"Otcorrelations": []string{"User=bob"}}
+	if !reflect.DeepEqual(header, want) {
+		t.Errorf("got header %v, want %v", header, want)
+	}
+
+	if _, err := bt.BaggageHeader(ot.NoopTracer{}.StartSpan("foreign").Context()); err != ot.ErrInvalidSpanContext {
+		t.Errorf("got error %v for a foreign span context, want %v", err, ot.ErrInvalidSpanContext)
+	}
+}
+
+func TestBaggageIterationOrder(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	header.Set("otcorrelations", "c=3,a=1,d=4,b=2")
+
+	keys := func(sc ot.SpanContext) []string {
+		var keys []string
+		sc.ForeachBaggageItem(func(k, _ string) bool {
+			keys = append(keys, k)
+			return true
+		})
+		return keys
+	}
+	for i := 0; i < 10; i++ {
+		sc, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+		if err != nil {
+			t.Fatalf("failed to extract the span context: %v", err)
+		}
+		if got, want := keys(sc), []string{"a", "b", "c", "d"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got extracted baggage order %v, want %v", got, want)
+		}
+
+		span := bt.StartSpan("test", ot.ChildOf(sc))
+		span.SetBaggageItem("added", "5")
+		if got, want := keys(span.Context()), []string{"a", "Added", "b", "c", "d"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got baggage order %v, want %v", got, want)
+		}
+	}
+}
+
+func TestExtractedBaggageKeyCasing(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	header.Set("otcorrelations", "userID=42,tenant=acme")
+	sc, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+	want := map[string]string{"userID": "42", "tenant": "acme"}
+	if got := baggageItems(sc); !reflect.DeepEqual(got, want) {
+		t.Errorf("got extracted baggage %v, want %v", got, want)
+	}
+
+	span := bt.StartSpan("test", ot.ChildOf(sc))
+	if got := span.BaggageItem("USERID"); got != "42" {
+		t.Errorf("got baggage item %q for a differently cased key, want %q", got, "42")
+	}
+	if got := baggageItems(span.Context()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got child baggage %v, want %v", got, want)
+	}
+
+	injected := http.Header{}
+	if err := bt.Inject(span.Context(), ot.HTTPHeaders, ot.HTTPHeadersCarrier(injected)); err != nil {
+		t.Fatalf("failed to inject the span context: %v", err)
+	}
+	members := strings.Split(injected.Get("otcorrelations"), ",")
+	sort.Strings(members)
+	if want := []string{"tenant=acme", "userID=42"}; !reflect.DeepEqual(members, want) {
+		t.Errorf("got injected baggage %v, want %v", members, want)
+	}
+
+	span.SetBaggageItem("userid", "43")
+	if got, want := baggageItems(span.Context()), map[string]string{"Userid": "43", "tenant": "acme"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got baggage %v after overwriting an extracted item, want %v", got, want)
+	}
+}
+
+func TestSampledBaggageKey(t *testing.T) {
+	bt, _ := newTestBridgeTracer(WithSampledBaggageKey("sampled"))
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	for _, sampled := range []bool{true, false} {
+		sc, err := NewRemoteSpanContext("4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", sampled)
+		if err != nil {
+			t.Fatalf("failed to create the span context: %v", err)
+		}
+		header := http.Header{}
+		if err := bt.Inject(sc, ot.HTTPHeaders, ot.HTTPHeadersCarrier(header)); err != nil {
+			t.Fatalf("failed to inject the span context: %v", err)
+		}
+		want := "sampled=0"
+		if sampled {
+			want = "sampled=1"
+		}
+		if got := header.Get("otcorrelations"); got != want {
+			t.Errorf("got injected baggage %q, want %q", got, want)
+		}
+
+		// Simulate a transport only carrying the baggage across by
+		// dropping the sampled flag from the traceparent.
+		header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+		extracted, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+		if err != nil {
+			t.Fatalf("failed to extract the span context: %v", err)
+		}
+		if got := extracted.(*bridgeSpanContext).otelSpanContext.IsSampled(); got != sampled {
+			t.Errorf("got extracted sampled flag %v, want %v", got, sampled)
+		}
+		if got := baggageItems(extracted); len(got) != 0 {
+			t.Errorf("got extracted baggage %v, want none", got)
+		}
+	}
+
+	bt, _ = newTestBridgeTracer()
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	header.Set("otcorrelations", "sampled=1")
+	extracted, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+	if extracted.(*bridgeSpanContext).otelSpanContext.IsSampled() {
+		t.Error("got a sampled span context without WithSampledBaggageKey")
+	}
+}
+
+func TestNameChangeEvents(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []BridgeOption
+		want []map[label.Key]label.Value
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name: "enabled",
+			opts: []BridgeOption{WithNameChangeEvents()},
+			want: []map[label.Key]label.Value{
+				{
+					renameEventOldKey: label.StringValue("GET"),
+					renameEventNewKey: label.StringValue("GET /users"),
+				},
+				{
+					renameEventOldKey: label.StringValue("GET /users"),
+					renameEventNewKey: label.StringValue("GET /users/{id}"),
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bt, sr := newTestBridgeTracer(tc.opts...)
+			span := bt.StartSpan("GET")
+			span.SetOperationName("GET /users")
+			span.SetOperationName("GET /users/{id}")
+			span.Finish()
+
+			otelSpan := sr.Completed()[0]
+			if got, want := otelSpan.Name(), "GET /users/{id}"; got != want {
+				t.Errorf("got span name %q, want %q", got, want)
+			}
+			var got []map[label.Key]label.Value
+			for _, e := range otelSpan.Events() {
+				if e.Name == renameEventName {
+					got = append(got, e.Attributes)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got rename events %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+type tenantKeyType struct{}
+
+var tenantKey tenantKeyType
+
+// tenantPropagator extracts the span context from the header named
+// after the tenant found in the context.
+type tenantPropagator struct{}
+
+func (tenantPropagator) Inject(context.Context, otel.TextMapCarrier) {}
+
+func (tenantPropagator) Extract(ctx context.Context, carrier otel.TextMapCarrier) context.Context {
+	tenant, _ := ctx.Value(tenantKey).(string)
+	if tenant == "" {
+		return ctx
+	}
+	ids := strings.Split(carrier.Get(tenant+"-trace"), "-")
+	if len(ids) != 2 {
+		return ctx
+	}
+	var sc otel.SpanContext
+	sc.TraceID, _ = otel.TraceIDFromHex(ids[0])
+	sc.SpanID, _ = otel.SpanIDFromHex(ids[1])
+	return otel.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+func (tenantPropagator) Fields() []string { return nil }
+
+func TestExtractContext(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	bt.SetTextMapPropagator(tenantPropagator{})
+
+	header := http.Header{}
+	header.Set("acme-trace", "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7")
+
+	if _, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header)); err != ot.ErrSpanContextNotFound {
+		t.Errorf("got error %v without a tenant in the context, want %v", err, ot.ErrSpanContextNotFound)
+	}
+
+	tracer := oteltest.NewTracerProvider().Tracer("")
+	callerCtx, _ := tracer.Start(context.WithValue(context.Background(), tenantKey, "acme"), "active")
+	ctx, sc, err := bt.ExtractContext(callerCtx, ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+	want := otel.SpanContext{
+		TraceID: otel.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  otel.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	}
+	if got := sc.(*bridgeSpanContext).otelSpanContext; got != want {
+		t.Errorf("got extracted span context %v, want %v", got, want)
+	}
+	if got := ctx.Value(tenantKey); got != "acme" {
+		t.Errorf("got tenant %v from the returned context, want it kept", got)
+	}
+	if got := otel.RemoteSpanContextFromContext(ctx); got != want {
+		t.Errorf("got remote span context %v from the returned context, want %v", got, want)
+	}
+	if got := otel.SpanFromContext(ctx).SpanContext(); got.IsValid() {
+		t.Errorf("got the active span %v of the caller in the returned context, want it hidden", got)
+	}
+
+	ctx, _, err = bt.ExtractContext(callerCtx, ot.HTTPHeaders, ot.HTTPHeadersCarrier(http.Header{}))
+	if err != ot.ErrSpanContextNotFound {
+		t.Errorf("got error %v for missing headers, want %v", err, ot.ErrSpanContextNotFound)
+	}
+	if ctx != callerCtx {
+		t.Error("got a different context on error, want the passed one")
+	}
+}
+
+func TestPropagationFields(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	got := bt.PropagationFields()
+	sort.Strings(got)
+	if want := []string{"otcorrelations", "traceparent", "tracestate"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got propagation fields %v, want %v", got, want)
+	}
+
+	bt.SetTextMapPropagator(propagators.Noop{})
+	if got := bt.PropagationFields(); len(got) != 0 {
+		t.Errorf("got propagation fields %v for the no-op propagator, want none", got)
+	}
+}
+
+func TestDefaultSpanName(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []BridgeOption
+		want string
+		warn bool
+	}{
+		{
+			name: "default",
+			want: "unnamed_span",
+			warn: true,
+		},
+		{
+			name: "custom",
+			opts: []BridgeOption{WithDefaultSpanName("fallback")},
+			want: "fallback",
+			warn: true,
+		},
+		{
+			name: "disabled",
+			opts: []BridgeOption{WithDefaultSpanName("")},
+			want: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bt, sr := newTestBridgeTracer(tc.opts...)
+			var warnings []string
+			bt.SetWarningHandler(func(msg string) { warnings = append(warnings, msg) })
+
+			bt.StartSpan("").Finish()
+			bt.StartSpan("").Finish()
+			bt.StartSpan("named").Finish()
+
+			spans := sr.Completed()
+			for _, s := range spans[:2] {
+				if s.Name() != tc.want {
+					t.Errorf("got span name %q, want %q", s.Name(), tc.want)
+				}
+			}
+			if got := spans[2].Name(); got != "named" {
+				t.Errorf("got span name %q, want %q", got, "named")
+			}
+			if tc.warn && len(warnings) != 1 {
+				t.Errorf("got warnings %q, want one", warnings)
+			}
+			if !tc.warn && len(warnings) != 0 {
+				t.Errorf("got warnings %q, want none", warnings)
+			}
+		})
+	}
+}
+
+func TestConcurrentPropagatorSwap(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	props := []otel.TextMapPropagator{
+		propagators.TraceContext{},
+		otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}),
+		nil,
+	}
+	sc, err := NewRemoteSpanContext("4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true)
+	if err != nil {
+		t.Fatalf("failed to create the span context: %v", err)
+	}
+
+	done := make(chan struct{})
+	swapped := make(chan struct{})
+	go func() {
+		defer close(swapped)
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+				bt.SetTextMapPropagator(props[i%len(props)])
+			}
+		}
+	}()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				header := http.Header{}
+				_ = bt.Inject(sc, ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+				_, _ = bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+				_ = bt.PropagationFields()
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	<-swapped
+}
+
+// startConfigTracer records the configuration and the remote parent
+// of the spans it starts.
+type startConfigTracer struct {
+	otel.Tracer
+
+	configs []*otel.SpanConfig
+	parents []otel.SpanContext
+}
+
+func (t *startConfigTracer) Start(ctx context.Context, name string, opts ...otel.SpanOption) (context.Context, otel.Span) {
+	t.configs = append(t.configs, otel.NewSpanConfig(opts...))
+	t.parents = append(t.parents, otel.RemoteSpanContextFromContext(ctx))
+	return t.Tracer.Start(ctx, name, opts...)
+}
+
+func TestZeroSamplingPriority(t *testing.T) {
+	parent, err := NewRemoteSpanContext("4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true)
+	if err != nil {
+		t.Fatalf("failed to create the span context: %v", err)
+	}
+	testCases := []struct {
+		name        string
+		priority    interface{}
+		wantRecord  bool
+		wantSampled bool
+	}{
+		{name: "no priority", wantRecord: true, wantSampled: true},
+		{name: "zero priority", priority: uint16(0), wantRecord: false, wantSampled: false},
+		{name: "zero int priority", priority: 0, wantRecord: false, wantSampled: false},
+		{name: "positive priority", priority: uint16(1), wantRecord: true, wantSampled: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tracer := &startConfigTracer{Tracer: oteltest.NewTracerProvider().Tracer("")}
+			bt := NewBridgeTracer()
+			bt.SetOpenTelemetryTracer(tracer)
+
+			tags := ot.Tags{}
+			if tc.priority != nil {
+				tags[string(otext.SamplingPriority)] = tc.priority
+			}
+			bt.StartSpan("test", ot.ChildOf(parent), tags).Finish()
+
+			if got := tracer.configs[0].Record; got != tc.wantRecord {
+				t.Errorf("got record %v, want %v", got, tc.wantRecord)
+			}
+			if got := tracer.parents[0].IsSampled(); got != tc.wantSampled {
+				t.Errorf("got sampled parent %v, want %v", got, tc.wantSampled)
+			}
+		})
+	}
+}
+
+func TestDefaultRecordPolicy(t *testing.T) {
+	defer SetDefaultRecordPolicy(AlwaysRecord)
+	parent, err := NewRemoteSpanContext("4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true)
+	if err != nil {
+		t.Fatalf("failed to create the span context: %v", err)
+	}
+	testCases := []struct {
+		name        string
+		policy      RecordPolicy
+		wantRecord  bool
+		wantSampled bool
+	}{
+		{name: "AlwaysRecord", policy: AlwaysRecord, wantRecord: true, wantSampled: true},
+		{name: "RespectSampler", policy: RespectSampler, wantRecord: false, wantSampled: true},
+		{name: "NeverRecord", policy: NeverRecord, wantRecord: false, wantSampled: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			before := NewBridgeTracer()
+			SetDefaultRecordPolicy(tc.policy)
+			bt := NewBridgeTracer()
+			SetDefaultRecordPolicy(AlwaysRecord)
+
+			tracer := &startConfigTracer{Tracer: oteltest.NewTracerProvider().Tracer("")}
+			bt.SetOpenTelemetryTracer(tracer)
+			before.SetOpenTelemetryTracer(tracer)
+			bt.StartSpan("test", ot.ChildOf(parent)).Finish()
+			before.StartSpan("before", ot.ChildOf(parent)).Finish()
+
+			if got := tracer.configs[0].Record; got != tc.wantRecord {
+				t.Errorf("got record %v, want %v", got, tc.wantRecord)
+			}
+			if got := tracer.parents[0].IsSampled(); got != tc.wantSampled {
+				t.Errorf("got sampled parent %v, want %v", got, tc.wantSampled)
+			}
+			if !tracer.configs[1].Record || !tracer.parents[1].IsSampled() {
+				t.Error("the policy changed for a tracer created before SetDefaultRecordPolicy")
+			}
+		})
+	}
+}
+
+func TestInjectToMap(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	header.Set("tracestate", "vendor=value")
+	sc, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+	span := bt.StartSpan("test", ot.ChildOf(sc))
+	span.SetBaggageItem("user", "bob")
+
+	got, err := bt.InjectToMap(span.Context())
+	if err != nil {
+		t.Fatalf("failed to inject the span context: %v", err)
+	}
+	injected := http.Header{}
+	if err := bt.Inject(span.Context(), ot.HTTPHeaders, ot.HTTPHeadersCarrier(injected)); err != nil {
+		t.Fatalf("failed to inject the span context: %v", err)
+	}
+	want := map[string]string{
+		"traceparent":    injected.Get("traceparent"),
+		"tracestate":     "vendor=value",
+		"otcorrelations": "User=bob",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got injected map %v, want %v", got, want)
+	}
+
+	foreign := ot.NoopTracer{}.StartSpan("foreign").Context()
+	if _, err := bt.InjectToMap(foreign); err != ot.ErrInvalidSpanContext {
+		t.Errorf("got error %v for a foreign span context, want %v", err, ot.ErrInvalidSpanContext)
+	}
+	bt, _ = newTestBridgeTracer(WithStrictInjectValidation(InjectValidationSilentNoop))
+	if got, err := bt.InjectToMap(foreign); err != nil || len(got) != 0 {
+		t.Errorf("got %v, %v for a foreign span context in silent mode, want an empty map", got, err)
+	}
+}
+
+func TestMaxEvents(t *testing.T) {
+	bt, sr := newTestBridgeTracer(WithMaxEvents(3))
+	var warnings []string
+	bt.SetWarningHandler(func(msg string) { warnings = append(warnings, msg) })
+
+	span := bt.StartSpan("loop")
+	for i := 0; i < 10; i++ {
+		span.LogFields(otlog.Int("iteration", i))
+	}
+	span.Finish()
+
+	if got, want := len(sr.Completed()[0].Events()), 3; got != want {
+		t.Errorf("got %d events, want %d", got, want)
+	}
+	if got, want := DroppedEvents(span), 7; got != want {
+		t.Errorf("got %d dropped events, want %d", got, want)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("got %d warnings, want 1: %v", len(warnings), warnings)
+	}
+
+	other := bt.StartSpan("other")
+	other.LogFields(otlog.String("a", "b"))
+	other.Finish()
+	if got := DroppedEvents(other); got != 0 {
+		t.Errorf("got %d dropped events for a span below the limit, want 0", got)
+	}
+
+	second := bt.StartSpan("second loop")
+	for i := 0; i < 5; i++ {
+		second.LogFields(otlog.Int("iteration", i))
+	}
+	second.Finish()
+	if len(warnings) != 2 {
+		t.Errorf("got %d warnings for two spans over the limit, want 2: %v", len(warnings), warnings)
+	}
+	if got := DroppedEvents(ot.NoopTracer{}.StartSpan("foreign")); got != 0 {
+		t.Errorf("got %d dropped events for a foreign span, want 0", got)
+	}
+}
+
+func TestInjectWithoutBaggage(t *testing.T) {
+	bt, _ := newTestBridgeTracer(WithSampledBaggageKey("sampled"))
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	header.Set("tracestate", "vendor=value")
+	sc, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+	span := bt.StartSpan("test", ot.ChildOf(sc))
+	span.SetBaggageItem("user", "bob")
+
+	withBaggage := http.Header{}
+	if err := bt.InjectWithOptions(span.Context(), ot.HTTPHeaders, ot.HTTPHeadersCarrier(withBaggage)); err != nil {
+		t.Fatalf("failed to inject the span context: %v", err)
+	}
+	if withBaggage.Get("otcorrelations") == "" {
+		t.Errorf("got no baggage header without options, want one")
+	}
+
+	withoutBaggage := http.Header{}
+	if err := bt.InjectWithOptions(span.Context(), ot.HTTPHeaders, ot.HTTPHeadersCarrier(withoutBaggage), WithoutBaggage()); err != nil {
+		t.Fatalf("failed to inject the span context: %v", err)
+	}
+	want := http.Header{
+		"Traceparent": withBaggage["Traceparent"],
+		"Tracestate":  []string{"vendor=value"},
+	}
+	if !reflect.DeepEqual(withoutBaggage, want) {
+		t.Errorf("got headers %v with WithoutBaggage, want %v", withoutBaggage, want)
+	}
+	if got := baggageItems(span.Context()); got["User"] != "bob" {
+		t.Errorf("got baggage %v after injecting without baggage, want the user item kept", got)
+	}
+}
+
+func TestInjectFromContext(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	t.Run("active bridge span", func(t *testing.T) {
+		span := bt.StartSpan("test")
+		span.SetBaggageItem("user", "bob")
+		ctx := ot.ContextWithSpan(context.Background(), span)
+
+		got := http.Header{}
+		if err := bt.InjectFromContext(ctx, ot.HTTPHeaders, ot.HTTPHeadersCarrier(got)); err != nil {
+			t.Fatalf("failed to inject from the context: %v", err)
+		}
+		want := http.Header{}
+		if err := bt.Inject(span.Context(), ot.HTTPHeaders, ot.HTTPHeadersCarrier(want)); err != nil {
+			t.Fatalf("failed to inject the span context: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got headers %v, want %v", got, want)
+		}
+	})
+
+	t.Run("active OpenTelemetry span", func(t *testing.T) {
+		ctx, otelSpan := oteltest.NewTracerProvider().Tracer("").Start(context.Background(), "otel")
+		ctx = baggage.NewContext(ctx, label.String("user", "alice"))
+
+		got := http.Header{}
+		if err := bt.InjectFromContext(ctx, ot.HTTPHeaders, ot.HTTPHeadersCarrier(got)); err != nil {
+			t.Fatalf("failed to inject from the context: %v", err)
+		}
+		sc := otelSpan.SpanContext()
+		want := http.Header{
+			"Traceparent":    []string{fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.TraceFlags&otel.FlagsSampled)},
+			"Otcorrelations": []string{"user=alice"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got headers %v, want %v", got, want)
+		}
+	})
+
+	t.Run("remote span context", func(t *testing.T) {
+		sc := otel.SpanContext{
+			TraceID:    otel.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+			SpanID:     otel.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			TraceFlags: otel.FlagsSampled,
+		}
+		ctx := otel.ContextWithRemoteSpanContext(context.Background(), sc)
+
+		got := http.Header{}
+		if err := bt.InjectFromContext(ctx, ot.HTTPHeaders, ot.HTTPHeadersCarrier(got)); err != nil {
+			t.Fatalf("failed to inject from the context: %v", err)
+		}
+		if want := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"; got.Get("traceparent") != want {
+			t.Errorf("got traceparent %q, want %q", got.Get("traceparent"), want)
+		}
+	})
+
+	t.Run("no active span", func(t *testing.T) {
+		err := bt.InjectFromContext(context.Background(), ot.HTTPHeaders, ot.HTTPHeadersCarrier(http.Header{}))
+		if err != ot.ErrSpanContextNotFound {
+			t.Errorf("got error %v, want %v", err, ot.ErrSpanContextNotFound)
+		}
+	})
+}
+
+func TestTimeTags(t *testing.T) {
+	bt, sr := newTestBridgeTracer()
+	deadline := time.Date(2020, time.October, 16, 12, 30, 0, 500, time.FixedZone("CEST", 2*60*60))
+	span := bt.StartSpan("test", ot.Tag{Key: "deadline", Value: deadline})
+	span.SetTag("timeout", 1500*time.Millisecond)
+	span.LogFields(otlog.Object("elapsed", 2*time.Second))
+	span.Finish()
+
+	otelSpan := sr.Completed()[0]
+	want := map[label.Key]label.Value{
+		"deadline": label.StringValue("2020-10-16T12:30:00.0000005+02:00"),
+		"timeout":  label.Int64Value(1500000000),
+	}
+	if got := otelSpan.Attributes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got attributes %v, want %v", got, want)
+	}
+	if got, want := otelSpan.Events()[0].Attributes["elapsed"], label.Int64Value(2000000000); got != want {
+		t.Errorf("got elapsed field %s(%s), want %s(%s)", got.Type(), got.Emit(), want.Type(), want.Emit())
+	}
+}
+
+func TestHasRemoteParent(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	bt.SetTextMapPropagator(propagators.TraceContext{})
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	remote, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+	entry := bt.StartSpan("entry", ot.ChildOf(remote))
+	local := bt.StartSpan("local", ot.ChildOf(entry.Context()))
+	followsRemote := bt.StartSpan("follows", ot.FollowsFrom(remote))
+	root := bt.StartSpan("root")
+	constructed, err := NewRemoteSpanContext("4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true)
+	if err != nil {
+		t.Fatalf("failed to create the remote span context: %v", err)
+	}
+	constructedEntry := bt.StartSpan("constructed", ot.ChildOf(constructed))
+	data, err := json.Marshal(entry.Context())
+	if err != nil {
+		t.Fatalf("failed to marshal the span context: %v", err)
+	}
+	parsed, err := ParseSpanContextJSON(data)
+	if err != nil {
+		t.Fatalf("failed to parse the span context: %v", err)
+	}
+	parsedEntry := bt.StartSpan("parsed", ot.ChildOf(parsed))
+
+	for _, tc := range []struct {
+		name string
+		span ot.Span
+		want bool
+	}{
+		{name: "remote parent", span: entry, want: true},
+		{name: "constructed remote parent", span: constructedEntry, want: true},
+		{name: "parsed remote parent", span: parsedEntry, want: true},
+		{name: "local parent", span: local, want: false},
+		{name: "remote link", span: followsRemote, want: false},
+		{name: "no parent", span: root, want: false},
+		{name: "foreign span", span: ot.NoopTracer{}.StartSpan("foreign"), want: false},
+	} {
+		if got := HasRemoteParent(tc.span); got != tc.want {
+			t.Errorf("%s: got HasRemoteParent %t, want %t", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestLinkToReference(t *testing.T) {
+	bt, sr := newTestBridgeTracer()
+	parent := bt.StartSpan("parent")
+	extra := bt.StartSpan("extra")
+	follows := bt.StartSpan("follows")
+	span := bt.StartSpan("test", ot.ChildOf(parent.Context()), ot.ChildOf(extra.Context()), ot.FollowsFrom(follows.Context()))
+	span.Finish()
+
+	links := sr.Completed()[0].LinksSlice()
+	want := map[otel.SpanContext]ot.SpanReferenceType{
+		otelSpanContextOf(extra):   ot.ChildOfRef,
+		otelSpanContextOf(follows): ot.FollowsFromRef,
+	}
+	if len(links) != len(want) {
+		t.Fatalf("got %d links, want %d", len(links), len(want))
+	}
+	var refs []ot.StartSpanOption
+	for _, link := range links {
+		ref := LinkToReference(link)
+		sc := ref.ReferencedContext.(*bridgeSpanContext).otelSpanContext
+		if wantType, ok := want[sc]; !ok || ref.Type != wantType {
+			t.Errorf("got reference of type %v to %v, want type %v", ref.Type, sc, wantType)
+		}
+		refs = append(refs, ref)
+	}
+
+	// The references make the same links again.
+	bt.StartSpan("again", append([]ot.StartSpanOption{ot.ChildOf(parent.Context())}, refs...)...).Finish()
+	if got := sr.Completed()[1].LinksSlice(); !reflect.DeepEqual(got, links) {
+		t.Errorf("got links %v from the converted references, want %v", got, links)
+	}
+
+	if got := LinkToReference(otel.Link{SpanContext: otelSpanContextOf(parent)}); got.Type != ot.FollowsFromRef {
+		t.Errorf("got reference type %v for a link without attributes, want %v", got.Type, ot.FollowsFromRef)
+	}
+}
+
+func otelSpanContextOf(span ot.Span) otel.SpanContext {
+	return span.Context().(*bridgeSpanContext).otelSpanContext
+}
+
+func TestMaxIncomingBaggageSize(t *testing.T) {
+	bt, _ := newTestBridgeTracer(WithMaxIncomingBaggageSize(20))
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+	var warnings []string
+	bt.SetWarningHandler(func(msg string) { warnings = append(warnings, msg) })
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	header.Set("otcorrelations", "a=12345,b=12345,c="+strings.Repeat("x", 1<<20)+",d=1")
+	for i := 0; i < 2; i++ {
+		sc, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+		if err != nil {
+			t.Fatalf("failed to extract the span context: %v", err)
+		}
+		want := map[string]string{"a": "12345", "b": "12345"}
+		if got := baggageItems(sc); !reflect.DeepEqual(got, want) {
+			t.Errorf("got baggage %v, want %v", got, want)
+		}
+	}
+	if len(warnings) != 1 {
+		t.Errorf("got %d warnings, want 1: %v", len(warnings), warnings)
+	}
+}
+
+func TestSpanContextJSON(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	header.Set("otcorrelations", "tenantId=acme")
+	parent, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+	span := bt.StartSpan("test", ot.ChildOf(parent))
+	SetBaggageItemWithProperties(span, "user", BaggageEntry{
+		Value:      "bob",
+		Properties: []BaggageProperty{{Key: "ttl", Value: "60"}},
+	})
+
+	data, err := json.Marshal(span.Context())
+	if err != nil {
+		t.Fatalf("failed to marshal the span context: %v", err)
+	}
+	spanID := otelSpanContextOf(span).SpanID
+	want := fmt.Sprintf(`{"version":1,"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"%s","trace_flags":"01","baggage":{"User":{"value":"bob","properties":[{"key":"ttl","value":"60"}]},"tenantId":{"value":"acme"}}}`, spanID)
+	if string(data) != want {
+		t.Errorf("got JSON %s, want %s", data, want)
+	}
+
+	parsed, err := ParseSpanContextJSON(data)
+	if err != nil {
+		t.Fatalf("failed to parse the span context: %v", err)
+	}
+	if got, want := parsed.(*bridgeSpanContext).otelSpanContext, otelSpanContextOf(span); got != want {
+		t.Errorf("got span context %v, want %v", got, want)
+	}
+	if got, want := baggageItems(parsed), baggageItems(span.Context()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got baggage %v, want %v", got, want)
+	}
+	again, err := json.Marshal(parsed)
+	if err != nil {
+		t.Fatalf("failed to marshal the parsed span context: %v", err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("got JSON %s after a round trip, want %s", again, data)
+	}
+
+	for _, invalid := range []string{
+		`{"version":2,"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","trace_flags":"01"}`,
+		`{"version":1,"trace_id":"00000000000000000000000000000000","span_id":"00f067aa0ba902b7","trace_flags":"01"}`,
+		`{"version":1,"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","trace_flags":"1"}`,
+		`not JSON`,
+	} {
+		if _, err := ParseSpanContextJSON([]byte(invalid)); err == nil {
+			t.Errorf("got no error parsing %s", invalid)
+		}
+	}
+}
+
+func TestSpanContextJSONBaggageRoundTrip(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	span := bt.StartSpan("test")
+	want := map[string]BaggageEntry{
+		"Separators": {
+			Value:      "a;b=c,d",
+			Properties: []BaggageProperty{{Key: "k;1", Value: "v=1;x"}, {Key: "flag"}},
+		},
+		"Spaces": {
+			Value:      " x ",
+			Properties: []BaggageProperty{{Key: " ttl ", Value: " 60 "}},
+		},
+	}
+	for k, entry := range want {
+		if !SetBaggageItemWithProperties(span, k, entry) {
+			t.Fatalf("failed to set the baggage item %q", k)
+		}
+	}
+
+	data, err := json.Marshal(span.Context())
+	if err != nil {
+		t.Fatalf("failed to marshal the span context: %v", err)
+	}
+	parsed, err := ParseSpanContextJSON(data)
+	if err != nil {
+		t.Fatalf("failed to parse the span context: %v", err)
+	}
+	for k, entry := range want {
+		if got, ok := BaggageItemEntry(parsed, k); !ok || !reflect.DeepEqual(got, entry) {
+			t.Errorf("got baggage item %q %+v (%t) after a round trip, want %+v", k, got, ok, entry)
+		}
+	}
+}
+
+func TestBinaryStream(t *testing.T) {
+	bt, _ := newTestBridgeTracer()
+	bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	first := bt.StartSpan("first")
+	first.SetBaggageItem("user", "bob")
+	second := bt.StartSpan("second")
+
+	stream := &bytes.Buffer{}
+	for _, span := range []ot.Span{first, second} {
+		if err := bt.Inject(span.Context(), ot.Binary, stream); err != nil {
+			t.Fatalf("failed to inject the span context: %v", err)
+		}
+	}
+	data := stream.Bytes()
+
+	t.Run("round trip", func(t *testing.T) {
+		r := iotest.OneByteReader(bytes.NewReader(data))
+		for _, span := range []ot.Span{first, second} {
+			sc, err := bt.Extract(ot.Binary, r)
+			if err != nil {
+				t.Fatalf("failed to extract the span context: %v", err)
+			}
+			want := span.Context().(*bridgeSpanContext).otelSpanContext
+			if got := sc.(*bridgeSpanContext).otelSpanContext; got != want {
+				t.Errorf("got span context %v, want %v", got, want)
+			}
+			if got, want := baggageItems(sc), baggageItems(span.Context()); !reflect.DeepEqual(got, want) {
+				t.Errorf("got baggage %v, want %v", got, want)
+			}
+		}
+		if _, err := bt.Extract(ot.Binary, r); err != ot.ErrSpanContextNotFound {
+			t.Errorf("got error %v at the end of the stream, want %v", err, ot.ErrSpanContextNotFound)
+		}
+	})
+
+	t.Run("empty stream", func(t *testing.T) {
+		if _, err := bt.Extract(ot.Binary, &bytes.Buffer{}); err != ot.ErrSpanContextNotFound {
+			t.Errorf("got error %v, want %v", err, ot.ErrSpanContextNotFound)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		for _, n := range []int{1, 3, 5, len(data) / 2} {
+			if _, err := bt.Extract(ot.Binary, bytes.NewReader(data[:n])); err != ot.ErrSpanContextCorrupted {
+				t.Errorf("got error %v for %d bytes, want %v", err, n, ot.ErrSpanContextCorrupted)
+			}
+		}
+	})
+
+	t.Run("invalid carrier", func(t *testing.T) {
+		if err := bt.Inject(first.Context(), ot.Binary, http.Header{}); err != ot.ErrInvalidCarrier {
+			t.Errorf("got inject error %v, want %v", err, ot.ErrInvalidCarrier)
+		}
+		if _, err := bt.Extract(ot.Binary, http.Header{}); err != ot.ErrInvalidCarrier {
+			t.Errorf("got extract error %v, want %v", err, ot.ErrInvalidCarrier)
+		}
+	})
+}
+
+func TestBaggageDirection(t *testing.T) {
+	tests := []struct {
+		name         string
+		direction    BaggageDirection
+		wantInbound  bool
+		wantOutbound bool
+	}{
+		{
+			name:         "both",
+			direction:    BaggageDirectionBoth,
+			wantInbound:  true,
+			wantOutbound: true,
+		},
+		{
+			name:        "inbound only",
+			direction:   BaggageDirectionInboundOnly,
+			wantInbound: true,
+		},
+		{
+			name:         "outbound only",
+			direction:    BaggageDirectionOutboundOnly,
+			wantOutbound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bt, _ := newTestBridgeTracer(WithBaggageDirection(tt.direction))
+			bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+			header := http.Header{}
+			header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			header.Set("otcorrelations", "tenant=acme")
+			ctx, sc, err := bt.ExtractContext(context.Background(), ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+			if err != nil {
+				t.Fatalf("failed to extract the span context: %v", err)
+			}
+			_, inCtx := baggage.MapFromContext(ctx).Value("tenant")
+			_, inSC := baggageItems(sc)["tenant"]
+			if inCtx != tt.wantInbound || inSC != tt.wantInbound {
+				t.Errorf("got extracted baggage in the context %t and in the span context %t, want %t", inCtx, inSC, tt.wantInbound)
+			}
+
+			span := bt.StartSpan("test", ot.ChildOf(sc))
+			span.SetBaggageItem("user", "bob")
+			injected := http.Header{}
+			if err := bt.Inject(span.Context(), ot.HTTPHeaders, ot.HTTPHeadersCarrier(injected)); err != nil {
+				t.Fatalf("failed to inject the span context: %v", err)
+			}
+			if got := injected.Get("otcorrelations") != ""; got != tt.wantOutbound {
+				t.Errorf("got injected baggage %t, want %t", got, tt.wantOutbound)
+			}
+			if injected.Get("traceparent") == "" {
+				t.Errorf("got no traceparent injected")
+			}
+			if got := baggageItems(span.Context())["User"]; got != "bob" {
+				t.Errorf("got baggage item %q, want the local item kept", got)
+			}
+		})
+	}
+}
+
+func TestStartChildFromExtracted(t *testing.T) {
+	bt, sr := newTestBridgeTracer()
+	bt.SetTextMapPropagator(propagators.TraceContext{})
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	extractedCtx, sc, err := bt.ExtractContext(context.WithValue(context.Background(), tenantKey, "acme"), ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+	if err != nil {
+		t.Fatalf("failed to extract the span context: %v", err)
+	}
+
+	span, ctx := bt.StartChildFromExtracted(extractedCtx, "server", sc, ot.Tag{Key: "component", Value: "http"})
+	parent := sc.(*bridgeSpanContext).otelSpanContext
+	otelSC := span.Context().(*bridgeSpanContext).otelSpanContext
+	if otelSC.TraceID != parent.TraceID {
+		t.Errorf("got trace ID %s, want the extracted %s", otelSC.TraceID, parent.TraceID)
+	}
+	if !HasRemoteParent(span) {
+		t.Error("got a span without a remote parent, want the extracted one")
+	}
+	if got := ot.SpanFromContext(ctx); got != span {
+		t.Errorf("got OpenTracing span %v from the context, want the started one", got)
+	}
+	if got := otel.SpanFromContext(ctx).SpanContext(); got != otelSC {
+		t.Errorf("got OpenTelemetry span context %v from the context, want %v", got, otelSC)
+	}
+	if got := ctx.Value(tenantKey); got != "acme" {
+		t.Errorf("got tenant %v from the context, want it kept", got)
+	}
+	span.Finish()
+
+	started := sr.Started()
+	if len(started) != 1 {
+		t.Fatalf("got %d spans started, want 1", len(started))
+	}
+	if got := started[0].ParentSpanID(); got != parent.SpanID {
+		t.Errorf("got parent span ID %s, want %s", got, parent.SpanID)
+	}
+	if got := started[0].Attributes()["component"]; got.AsString() != "http" {
+		t.Errorf("got component attribute %v, want the passed tag", got)
+	}
+
+	root, _ := bt.StartChildFromExtracted(context.Background(), "root", nil)
+	if HasRemoteParent(root) || root.Context().(*bridgeSpanContext).otelSpanContext.TraceID == parent.TraceID {
+		t.Error("got a child of the extracted span context without a parent, want a root span")
+	}
+}
+
+func TestBaggageValueSanitizer(t *testing.T) {
+	values := map[string]string{
+		"comma":     "acme, inc.",
+		"equals":    "a=b",
+		"semicolon": "v;p=1",
+		"control":   "line\r\nbreak\x00\x7f",
+	}
+
+	t.Run("default", func(t *testing.T) {
+		bt, _ := newTestBridgeTracer()
+		bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+		span := bt.StartSpan("test")
+		for k, v := range values {
+			span.SetBaggageItem(k, v)
+		}
+		want := map[string]string{
+			"Comma":     "acme, inc.",
+			"Equals":    "a=b",
+			"Semicolon": "v;p=1",
+			"Control":   "linebreak",
+		}
+		if got := baggageItems(span.Context()); !reflect.DeepEqual(got, want) {
+			t.Errorf("got baggage %v, want %v", got, want)
+		}
+		if got := PendingBaggageItems(span)["control"]; got != "linebreak" {
+			t.Errorf("got pending baggage item %q, want the sanitized value", got)
+		}
+
+		header := http.Header{}
+		if err := bt.Inject(span.Context(), ot.HTTPHeaders, ot.HTTPHeadersCarrier(header)); err != nil {
+			t.Fatalf("failed to inject the span context: %v", err)
+		}
+		if members := strings.Split(header.Get("otcorrelations"), ","); len(members) != len(want) {
+			t.Errorf("got baggage header members %q, want %d", members, len(want))
+		}
+		sc, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+		if err != nil {
+			t.Fatalf("failed to extract the span context: %v", err)
+		}
+		if got := baggageItems(sc); !reflect.DeepEqual(got, want) {
+			t.Errorf("got extracted baggage %v, want %v", got, want)
+		}
+		if entry, _ := BaggageItemEntry(sc, "semicolon"); len(entry.Properties) != 0 {
+			t.Errorf("got properties %v for a value with a semicolon, want none", entry.Properties)
+		}
+	})
+
+	t.Run("with properties", func(t *testing.T) {
+		bt, _ := newTestBridgeTracer()
+		span := bt.StartSpan("test")
+		entry := BaggageEntry{Value: "a,b\n", Properties: []BaggageProperty{{Key: "ttl", Value: "60"}}}
+		if !SetBaggageItemWithProperties(span, "user", entry) {
+			t.Fatal("SetBaggageItemWithProperties failed for a bridge span")
+		}
+		got, _ := BaggageItemEntry(span.Context(), "user")
+		want := BaggageEntry{Value: "a,b", Properties: entry.Properties}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got baggage entry %v, want %v", got, want)
+		}
+	})
+
+	t.Run("separators round trip", func(t *testing.T) {
+		bt, _ := newTestBridgeTracer()
+		bt.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+		span := bt.StartSpan("test")
+		span.SetBaggageItem("k", "a,b;c")
+		if got := span.BaggageItem("k"); got != "a,b;c" {
+			t.Errorf("got baggage item %q, want it unchanged", got)
+		}
+
+		header := http.Header{}
+		if err := bt.Inject(span.Context(), ot.HTTPHeaders, ot.HTTPHeadersCarrier(header)); err != nil {
+			t.Fatalf("failed to inject the span context: %v", err)
+		}
+		if got, want := header.Get("otcorrelations"), "K=a%2Cb%3Bc"; got != want {
+			t.Errorf("got baggage header %q, want %q", got, want)
+		}
+		sc, err := bt.Extract(ot.HTTPHeaders, ot.HTTPHeadersCarrier(header))
+		if err != nil {
+			t.Fatalf("failed to extract the span context: %v", err)
+		}
+		if got := baggageItems(sc); !reflect.DeepEqual(got, map[string]string{"K": "a,b;c"}) {
+			t.Errorf("got extracted baggage %v, want the original value", got)
+		}
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		bt, _ := newTestBridgeTracer(WithBaggageValueSanitizer(strings.ToUpper))
+		span := bt.StartSpan("test")
+		span.SetBaggageItem("user", "bob, jr.")
+		if got := span.BaggageItem("user"); got != "BOB, JR." {
+			t.Errorf("got baggage item %q, want the custom sanitized value", got)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		bt, _ := newTestBridgeTracer(WithBaggageValueSanitizer(func(v string) string { return v }))
+		span := bt.StartSpan("test")
+		for k, v := range values {
+			span.SetBaggageItem(k, v)
+			if got := span.BaggageItem(k); got != v {
+				t.Errorf("got baggage item %q, want the unchanged %q", got, v)
+			}
+		}
+	})
+}
@@ -0,0 +1,521 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opentracing
+
+import "sync/atomic"
+
+// BaggageTruncationPolicy describes what the BridgeTracer does when
+// adding a baggage item would exceed the configured maximum baggage
+// size or number of baggage items.
+type BaggageTruncationPolicy int
+
+const (
+	// BaggageTruncationRefuseNew keeps the existing baggage items
+	// and drops the item being added.
+	BaggageTruncationRefuseNew BaggageTruncationPolicy = iota
+	// BaggageTruncationDropOldest drops the least recently set
+	// baggage items until the item being added fits.
+	BaggageTruncationDropOldest
+)
+
+// InjectValidationMode describes what the Inject method of a
+// BridgeTracer does with an invalid span context.
+type InjectValidationMode int
+
+const (
+	// InjectValidationReturnError makes Inject return
+	// opentracing.ErrInvalidSpanContext.
+	InjectValidationReturnError InjectValidationMode = iota
+	// InjectValidationSilentNoop makes Inject inject nothing and
+	// return no error.
+	InjectValidationSilentNoop
+	// InjectValidationPanic makes Inject panic, which surfaces the
+	// bug early during development.
+	InjectValidationPanic
+)
+
+// RecordPolicy describes whether the spans started by a BridgeTracer
+// record their data.
+type RecordPolicy int32
+
+const (
+	// AlwaysRecord forces the spans to record, whatever the sampling
+	// decision of the OpenTelemetry tracer.
+	AlwaysRecord RecordPolicy = iota
+	// RespectSampler leaves the recording decision to the sampler of
+	// the OpenTelemetry tracer.
+	RespectSampler
+	// NeverRecord does not force the spans to record and passes their
+	// parent to the OpenTelemetry tracer as unsampled, like a zero
+	// sampling.priority tag, so a parent-based sampler drops them.
+	NeverRecord
+)
+
+// BaggageDirection describes in which directions a BridgeTracer
+// propagates baggage.
+type BaggageDirection int
+
+const (
+	// BaggageDirectionBoth makes Extract read the baggage from the
+	// carrier and Inject write it.
+	BaggageDirectionBoth BaggageDirection = iota
+	// BaggageDirectionInboundOnly makes Extract read the baggage from
+	// the carrier while Inject never writes it, for a service that
+	// consumes the baggage without forwarding it.
+	BaggageDirectionInboundOnly
+	// BaggageDirectionOutboundOnly makes Inject write the baggage
+	// while Extract ignores the baggage of the carrier, for a service
+	// at a trust boundary.
+	BaggageDirectionOutboundOnly
+)
+
+func (d BaggageDirection) inbound() bool {
+	return d != BaggageDirectionOutboundOnly
+}
+
+func (d BaggageDirection) outbound() bool {
+	return d != BaggageDirectionInboundOnly
+}
+
+// defaultRecordPolicy is the RecordPolicy of the BridgeTracers created
+// from now on. It is accessed atomically.
+var defaultRecordPolicy = int32(AlwaysRecord)
+
+// SetDefaultRecordPolicy sets the RecordPolicy of the BridgeTracers
+// created after the call, which helps switching the behavior of a whole
+// application during a migration. The BridgeTracers already created
+// keep their policy. The default is AlwaysRecord. It is safe to call
+// concurrently with NewBridgeTracer.
+func SetDefaultRecordPolicy(policy RecordPolicy) {
+	atomic.StoreInt32(&defaultRecordPolicy, int32(policy))
+}
+
+// defaultSpanName is the name of the spans started with an empty
+// operation name, unless changed with WithDefaultSpanName.
+const defaultSpanName = "unnamed_span"
+
+type config struct {
+	// maxBaggageSize is the maximum total size in bytes of the
+	// baggage items of a span context. Zero means no limit.
+	maxBaggageSize int
+	// maxBaggageItems is the maximum number of baggage items of a
+	// span context. Zero means no limit.
+	maxBaggageItems int
+	// baggageTruncationPolicy decides which baggage items are
+	// dropped when maxBaggageSize or maxBaggageItems would be
+	// exceeded.
+	baggageTruncationPolicy BaggageTruncationPolicy
+	// eventTags are the keys of the tags that are recorded as
+	// span events instead of span attributes.
+	eventTags map[string]struct{}
+	// httpStatusToSpanStatus makes the HTTP status code tag set
+	// an Error span status for failed requests.
+	httpStatusToSpanStatus bool
+	// semanticTags makes the tags with keys that have a semantic
+	// convention counterpart recorded under the semantic convention
+	// key.
+	semanticTags bool
+	// keepOriginalTags makes the mapped tags recorded under their
+	// original key too.
+	keepOriginalTags bool
+	// caseSensitiveTagMapping makes the semantic convention mapping
+	// only match tag keys with the exact casing.
+	caseSensitiveTagMapping bool
+	// baggageDebugEvents makes every SetBaggageItem call recorded
+	// as a span event.
+	baggageDebugEvents bool
+	// baggageRedactor returns the value recorded in the baggage
+	// debug events. Nil means the default redaction.
+	baggageRedactor func(key, value string) string
+	// injectValidation decides how Inject handles an invalid span
+	// context.
+	injectValidation InjectValidationMode
+	// sampledBaggageKey is the key of the baggage item carrying the
+	// sampled flag. Empty means the flag is not put in the baggage.
+	sampledBaggageKey string
+	// nameChangeEvents makes every SetOperationName call recorded as
+	// a span event.
+	nameChangeEvents bool
+	// defaultSpanName is the name of the spans started with an empty
+	// operation name.
+	defaultSpanName string
+	// maxEvents is the maximum number of events the bridge adds to a
+	// span. Zero means no limit.
+	maxEvents int
+	// recordPolicy decides whether the spans are forced to record.
+	recordPolicy RecordPolicy
+	// maxIncomingBaggageSize is the maximum total size in bytes of the
+	// baggage items Extract keeps. Zero means no limit.
+	maxIncomingBaggageSize int
+	// baggageDirection decides whether Extract reads the baggage and
+	// whether Inject writes it.
+	baggageDirection BaggageDirection
+	// baggageSanitizer returns the value stored for a baggage item set
+	// on a span. Nil means the default sanitization.
+	baggageSanitizer func(value string) string
+}
+
+func newConfig(opts ...BridgeOption) config {
+	conf := config{
+		defaultSpanName: defaultSpanName,
+		recordPolicy:    RecordPolicy(atomic.LoadInt32(&defaultRecordPolicy)),
+	}
+	for _, opt := range opts {
+		opt.Apply(&conf)
+	}
+	return conf
+}
+
+// BridgeOption applies an option to a BridgeTracer configuration.
+type BridgeOption interface {
+	Apply(*config)
+}
+
+type maxBaggageSizeOption int
+
+func (o maxBaggageSizeOption) Apply(c *config) {
+	c.maxBaggageSize = int(o)
+}
+
+// WithMaxBaggageSize limits the total size of the baggage items of a
+// span context. The size of a baggage item is the length of its key
+// plus the length of its value. A non-positive size disables the
+// limit, which is the default.
+func WithMaxBaggageSize(size int) BridgeOption {
+	return maxBaggageSizeOption(size)
+}
+
+type maxBaggageItemsOption int
+
+func (o maxBaggageItemsOption) Apply(c *config) {
+	c.maxBaggageItems = int(o)
+}
+
+// WithMaxBaggageItems limits the number of baggage items of a span
+// context. It applies together with WithMaxBaggageSize, an item is
+// only added if both limits hold afterwards. A non-positive number
+// disables the limit, which is the default.
+func WithMaxBaggageItems(n int) BridgeOption {
+	return maxBaggageItemsOption(n)
+}
+
+type baggageTruncationPolicyOption BaggageTruncationPolicy
+
+func (o baggageTruncationPolicyOption) Apply(c *config) {
+	c.baggageTruncationPolicy = BaggageTruncationPolicy(o)
+}
+
+// WithBaggageTruncationPolicy sets the policy used when adding a
+// baggage item would exceed the size configured with
+// WithMaxBaggageSize or the number of items configured with
+// WithMaxBaggageItems. The default is BaggageTruncationRefuseNew.
+func WithBaggageTruncationPolicy(policy BaggageTruncationPolicy) BridgeOption {
+	return baggageTruncationPolicyOption(policy)
+}
+
+type highCardinalityTagsOption []string
+
+func (o highCardinalityTagsOption) Apply(c *config) {
+	if c.eventTags == nil {
+		c.eventTags = make(map[string]struct{}, len(o))
+	}
+	for _, k := range o {
+		c.eventTags[k] = struct{}{}
+	}
+}
+
+// WithHighCardinalityTagsAsEvents makes the BridgeTracer record the
+// tags with the passed keys as attributes of a span event instead of
+// span attributes. This keeps high cardinality values like request or
+// user IDs out of the span attributes. Other tags are recorded as
+// span attributes.
+func WithHighCardinalityTagsAsEvents(keys []string) BridgeOption {
+	return highCardinalityTagsOption(keys)
+}
+
+type httpStatusToSpanStatusOption bool
+
+func (o httpStatusToSpanStatusOption) Apply(c *config) {
+	c.httpStatusToSpanStatus = bool(o)
+}
+
+// WithHTTPStatusToSpanStatus makes the BridgeTracer derive the status
+// of a span from the OpenTracing http.status_code tag. A status code
+// of 500 or above sets an Error status. For spans of the client kind,
+// a status code of 400 or above sets an Error status too. Other status
+// codes leave the span status unchanged. The tag is still recorded as
+// a span attribute.
+func WithHTTPStatusToSpanStatus() BridgeOption {
+	return httpStatusToSpanStatusOption(true)
+}
+
+type semanticConventionTagsOption bool
+
+func (o semanticConventionTagsOption) Apply(c *config) {
+	c.semanticTags = bool(o)
+}
+
+// WithSemanticConventionTags makes the BridgeTracer record the
+// OpenTracing tags whose keys differ from the OpenTelemetry semantic
+// conventions under the semantic convention keys. For example the
+// peer.hostname tag is recorded as the net.peer.name attribute. Tags
+// without a semantic convention counterpart are recorded as is. The
+// tag keys are matched case-insensitively, so PEER.HOSTNAME is mapped
+// to net.peer.name too, see WithCaseSensitiveTagMapping.
+func WithSemanticConventionTags() BridgeOption {
+	return semanticConventionTagsOption(true)
+}
+
+type keepOriginalTagsOption bool
+
+func (o keepOriginalTagsOption) Apply(c *config) {
+	c.keepOriginalTags = bool(o)
+}
+
+// WithKeepOriginalTags makes the BridgeTracer record the tags mapped
+// by WithSemanticConventionTags under their original keys too, which
+// keeps existing dashboards working during a migration. By default
+// the mapped tags replace the original ones. This option has no effect
+// without WithSemanticConventionTags.
+func WithKeepOriginalTags() BridgeOption {
+	return keepOriginalTagsOption(true)
+}
+
+type baggageDebugEventsOption bool
+
+func (o baggageDebugEventsOption) Apply(c *config) {
+	c.baggageDebugEvents = bool(o)
+}
+
+// WithBaggageDebugEvents makes the BridgeTracer record a span event
+// named "ot-baggage" for every SetBaggageItem call on a span. The
+// event has the baggage.key, baggage.value and baggage.accepted
+// attributes, the last one being false if the item did not fit in the
+// maximum baggage size. The value is redacted, see
+// WithBaggageValueRedactor. This is meant for debugging baggage
+// propagation problems; nothing is recorded without this option.
+func WithBaggageDebugEvents() BridgeOption {
+	return baggageDebugEventsOption(true)
+}
+
+type baggageRedactorOption func(key, value string) string
+
+func (o baggageRedactorOption) Apply(c *config) {
+	c.baggageRedactor = o
+}
+
+// WithBaggageValueRedactor sets the function returning the value
+// recorded in the events of WithBaggageDebugEvents for a baggage item.
+// By default the value is replaced with "[redacted]". A function
+// returning the passed value unchanged disables the redaction.
+func WithBaggageValueRedactor(redact func(key, value string) string) BridgeOption {
+	return baggageRedactorOption(redact)
+}
+
+type baggageSanitizerOption func(value string) string
+
+func (o baggageSanitizerOption) Apply(c *config) {
+	c.baggageSanitizer = o
+}
+
+// WithBaggageValueSanitizer sets the function applied to the value of
+// every baggage item set on a span with SetBaggageItem or
+// SetBaggageItemWithProperties, or inherited from the span context of
+// another tracer, before it is stored. It is not applied to extracted
+// baggage nor to baggage set in the OpenTelemetry context. By default
+// the ASCII control characters are removed, the escaping of the
+// separators of the baggage header being left to the propagator. A
+// function returning the passed value unchanged disables the
+// sanitization.
+func WithBaggageValueSanitizer(sanitize func(value string) string) BridgeOption {
+	return baggageSanitizerOption(sanitize)
+}
+
+// sanitizeBaggageValue applies the configured baggage value sanitizer.
+func (c config) sanitizeBaggageValue(value string) string {
+	if c.baggageSanitizer == nil {
+		return sanitizeBaggageValue(value)
+	}
+	return c.baggageSanitizer(value)
+}
+
+type injectValidationOption InjectValidationMode
+
+func (o injectValidationOption) Apply(c *config) {
+	c.injectValidation = InjectValidationMode(o)
+}
+
+// WithStrictInjectValidation sets how the BridgeTracer handles a span
+// context passed to Inject that was not created by a BridgeTracer or
+// that is invalid. The default is InjectValidationReturnError.
+func WithStrictInjectValidation(mode InjectValidationMode) BridgeOption {
+	return injectValidationOption(mode)
+}
+
+type sampledBaggageKeyOption string
+
+func (o sampledBaggageKeyOption) Apply(c *config) {
+	c.sampledBaggageKey = string(o)
+}
+
+// WithSampledBaggageKey makes the BridgeTracer propagate the sampled
+// flag of a span context in the baggage too, for transports that only
+// carry the baggage across. Inject adds a baggage item with the passed
+// key and the value "1" for a sampled span context or "0" otherwise.
+// Extract sets the sampled flag of the extracted span context if the
+// flag is unset and the item is "1", and does not expose the item as
+// a baggage item. An empty key disables this, which is the default.
+func WithSampledBaggageKey(key string) BridgeOption {
+	return sampledBaggageKeyOption(key)
+}
+
+type nameChangeEventsOption bool
+
+func (o nameChangeEventsOption) Apply(c *config) {
+	c.nameChangeEvents = bool(o)
+}
+
+// WithNameChangeEvents makes the BridgeTracer record a span event named
+// "span.rename" for every SetOperationName call on a span, which helps
+// auditing late renames like the templating of a route. The event has
+// the old and new attributes holding the previous and the new
+// operation name. The old name is empty for a span set up with
+// ContextWithBridgeSpan, as its name is not known to the bridge.
+// Nothing is recorded without this option.
+func WithNameChangeEvents() BridgeOption {
+	return nameChangeEventsOption(true)
+}
+
+type defaultSpanNameOption string
+
+func (o defaultSpanNameOption) Apply(c *config) {
+	c.defaultSpanName = string(o)
+}
+
+// WithDefaultSpanName sets the name of the spans started with an empty
+// operation name, which is usually an instrumentation bug. The
+// BridgeTracer warns once when it uses the default name. The default
+// is "unnamed_span". An empty name makes the spans keep their empty
+// name.
+func WithDefaultSpanName(name string) BridgeOption {
+	return defaultSpanNameOption(name)
+}
+
+type maxEventsOption int
+
+func (o maxEventsOption) Apply(c *config) {
+	c.maxEvents = int(o)
+}
+
+// WithMaxEvents limits the number of events the BridgeTracer adds to
+// the OpenTelemetry span behind an OpenTracing span, like the events
+// of LogFields calls, which protects against instrumentation logging
+// in a loop. The events past the limit are dropped and counted, see
+// DroppedEvents, and a warning is emitted once per span. A
+// non-positive limit disables it, which is the default.
+func WithMaxEvents(n int) BridgeOption {
+	return maxEventsOption(n)
+}
+
+type maxIncomingBaggageSizeOption int
+
+func (o maxIncomingBaggageSizeOption) Apply(c *config) {
+	c.maxIncomingBaggageSize = int(o)
+}
+
+// WithMaxIncomingBaggageSize limits the total size of the baggage items
+// Extract keeps from the carrier, which protects against an upstream
+// sending megabytes of baggage. The size of a baggage item is the
+// length of its key plus the length of its value. The items are
+// accounted in the order of their keys and the ones past the limit are
+// dropped, with a warning emitted once. A non-positive size disables
+// the limit, which is the default. See WithMaxBaggageSize for a limit
+// applying to all the baggage items.
+func WithMaxIncomingBaggageSize(size int) BridgeOption {
+	return maxIncomingBaggageSizeOption(size)
+}
+
+type caseSensitiveTagMappingOption bool
+
+func (o caseSensitiveTagMappingOption) Apply(c *config) {
+	c.caseSensitiveTagMapping = bool(o)
+}
+
+// WithCaseSensitiveTagMapping makes the mapping enabled with
+// WithSemanticConventionTags only apply to tags whose keys have the
+// casing used by the OpenTracing conventions. Other tags are recorded
+// as is.
+func WithCaseSensitiveTagMapping() BridgeOption {
+	return caseSensitiveTagMappingOption(true)
+}
+
+type baggageDirectionOption BaggageDirection
+
+func (o baggageDirectionOption) Apply(c *config) {
+	c.baggageDirection = BaggageDirection(o)
+}
+
+// WithBaggageDirection sets whether the baggage is read from the
+// carrier by Extract and whether it is written to the carrier by
+// Inject, InjectToMap and BaggageHeader, including the item of
+// WithSampledBaggageKey. The baggage items of the span contexts are
+// kept either way. The default is BaggageDirectionBoth.
+func WithBaggageDirection(direction BaggageDirection) BridgeOption {
+	return baggageDirectionOption(direction)
+}
+
+func (c config) isEventTag(key string) bool {
+	_, ok := c.eventTags[key]
+	return ok
+}
+
+type injectConfig struct {
+	// withoutBaggage makes the injection skip the baggage.
+	withoutBaggage bool
+}
+
+func newInjectConfig(opts ...InjectOption) injectConfig {
+	var conf injectConfig
+	for _, opt := range opts {
+		opt.Apply(&conf)
+	}
+	return conf
+}
+
+// InjectOption applies an option to a single
+// BridgeTracer.InjectWithOptions call.
+type InjectOption interface {
+	Apply(*injectConfig)
+}
+
+type withoutBaggageOption bool
+
+func (o withoutBaggageOption) Apply(c *injectConfig) {
+	c.withoutBaggage = bool(o)
+}
+
+// WithoutBaggage makes InjectWithOptions inject the trace context of
+// the span context without its baggage items, including the item of
+// WithSampledBaggageKey.
+func WithoutBaggage() InjectOption {
+	return withoutBaggageOption(true)
+}
+
+// baggageLimitsExceeded tells whether baggage items of the passed total
+// size and number exceed the configured limits.
+func (c config) baggageLimitsExceeded(size, items int) bool {
+	return (c.maxBaggageSize > 0 && size > c.maxBaggageSize) ||
+		(c.maxBaggageItems > 0 && items > c.maxBaggageItems)
+}
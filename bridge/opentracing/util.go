@@ -16,8 +16,15 @@ package opentracing
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	ot "github.com/opentracing/opentracing-go"
 
 	"go.opentelemetry.io/otel"
+	otelglobal "go.opentelemetry.io/otel/global"
 )
 
 // NewTracerPair is a utility function that creates a BridgeTracer and a
@@ -25,16 +32,173 @@ import (
 // WrapperTracer. The BridgeTracer forwards the calls to the WrapperTracer
 // that wraps the passed tracer. BridgeTracer and WrapperTracerProvider are
 // returned to the caller and the caller is expected to register BridgeTracer
-// with opentracing and WrapperTracerProvider with opentelemetry.
-func NewTracerPair(tracer otel.Tracer) (*BridgeTracer, *WrapperTracerProvider) {
-	bridgeTracer := NewBridgeTracer()
+// with opentracing and WrapperTracerProvider with opentelemetry. The
+// passed options are used to configure the BridgeTracer.
+func NewTracerPair(tracer otel.Tracer, opts ...BridgeOption) (*BridgeTracer, *WrapperTracerProvider) {
+	bridgeTracer := NewBridgeTracer(opts...)
 	wrapperProvider := NewWrappedTracerProvider(bridgeTracer, tracer)
 	bridgeTracer.SetOpenTelemetryTracer(wrapperProvider.Tracer(""))
 	return bridgeTracer, wrapperProvider
 }
 
-func NewTracerPairWithContext(ctx context.Context, tracer otel.Tracer) (context.Context, *BridgeTracer, *WrapperTracerProvider) {
-	bridgeTracer, wrapperProvider := NewTracerPair(tracer)
+func NewTracerPairWithContext(ctx context.Context, tracer otel.Tracer, opts ...BridgeOption) (context.Context, *BridgeTracer, *WrapperTracerProvider) {
+	bridgeTracer, wrapperProvider := NewTracerPair(tracer, opts...)
 	ctx = bridgeTracer.NewHookedContext(ctx)
 	return ctx, bridgeTracer, wrapperProvider
 }
+
+// Install creates a pair of BridgeTracer and WrapperTracerProvider
+// with NewTracerPair, registers the BridgeTracer as the global
+// OpenTracing tracer and the WrapperTracerProvider as the global
+// OpenTelemetry tracer provider. If the passed propagator is not nil,
+// it is used by the BridgeTracer and also set as the global
+// OpenTelemetry propagator. The passed options are used to configure
+// the BridgeTracer.
+//
+// Install is meant to be called once, when the program starts. It
+// cannot be undone: the tracers and propagators obtained from the
+// OpenTelemetry globals before the first provider or propagator is set
+// forward to the installed ones for the lifetime of the program.
+//
+// Install has no context to hook, so a context used to mix the
+// OpenTracing and OpenTelemetry APIs still needs to be passed through
+// the NewHookedContext method of the returned BridgeTracer.
+func Install(tracer otel.Tracer, propagator otel.TextMapPropagator, opts ...BridgeOption) *BridgeTracer {
+	bridgeTracer, wrapperProvider := NewTracerPair(tracer, opts...)
+	otelglobal.SetTracerProvider(wrapperProvider)
+	if propagator != nil {
+		bridgeTracer.SetTextMapPropagator(propagator)
+		otelglobal.SetTextMapPropagator(propagator)
+	}
+	ot.SetGlobalTracer(bridgeTracer)
+	return bridgeTracer
+}
+
+// NewRemoteSpanContext returns an OpenTracing span context of a remote
+// span with the passed hex encoded trace and span IDs. It can be passed
+// to the Inject method of a BridgeTracer or used as a reference when
+// starting a span, without extracting it from a carrier first. An
+// error is returned if an ID is malformed or invalid.
+func NewRemoteSpanContext(traceIDHex, spanIDHex string, sampled bool) (ot.SpanContext, error) {
+	traceID, err := otel.TraceIDFromHex(traceIDHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trace ID %q: %w", traceIDHex, err)
+	}
+	spanID, err := otel.SpanIDFromHex(spanIDHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid span ID %q: %w", spanIDHex, err)
+	}
+	sc := otel.SpanContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}
+	if sampled {
+		sc.TraceFlags = otel.FlagsSampled
+	}
+	bridgeSC := newBridgeSpanContext(sc, nil, config{})
+	bridgeSC.remote = true
+	return bridgeSC, nil
+}
+
+// spanContextJSONVersion is the version of the JSON representation of
+// the span contexts. It changes if the representation changes in an
+// incompatible way.
+const spanContextJSONVersion = 1
+
+// spanContextJSON is the JSON representation of a span context created
+// by a BridgeTracer.
+type spanContextJSON struct {
+	Version    int    `json:"version"`
+	TraceID    string `json:"trace_id"`
+	SpanID     string `json:"span_id"`
+	TraceFlags string `json:"trace_flags"`
+	// Baggage holds the baggage items with their exact keys.
+	Baggage map[string]baggageEntryJSON `json:"baggage,omitempty"`
+}
+
+// baggageEntryJSON is the JSON representation of a baggage item. The
+// value and the properties are kept apart, so they are parsed back
+// exactly whatever characters they contain.
+type baggageEntryJSON struct {
+	Value      string                `json:"value"`
+	Properties []baggagePropertyJSON `json:"properties,omitempty"`
+}
+
+// baggagePropertyJSON is the JSON representation of a BaggageProperty.
+type baggagePropertyJSON struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+// MarshalJSON returns the JSON representation of c, which
+// ParseSpanContextJSON parses back. It holds the version of the
+// representation, the hex encoded trace ID, span ID and trace flags
+// and the baggage items.
+func (c *bridgeSpanContext) MarshalJSON() ([]byte, error) {
+	sc := spanContextJSON{
+		Version:    spanContextJSONVersion,
+		TraceID:    c.otelSpanContext.TraceID.String(),
+		SpanID:     c.otelSpanContext.SpanID.String(),
+		TraceFlags: hex.EncodeToString([]byte{c.otelSpanContext.TraceFlags}),
+	}
+	if len(c.baggageOrder) > 0 {
+		sc.Baggage = make(map[string]baggageEntryJSON, len(c.baggageOrder))
+		for _, k := range c.baggageOrder {
+			entry := c.baggageEntry(k)
+			entryJSON := baggageEntryJSON{Value: entry.Value}
+			for _, p := range entry.Properties {
+				entryJSON.Properties = append(entryJSON.Properties, baggagePropertyJSON(p))
+			}
+			sc.Baggage[c.baggageKey(k)] = entryJSON
+		}
+	}
+	return json.Marshal(sc)
+}
+
+// ParseSpanContextJSON parses the JSON representation of a span context
+// created by a BridgeTracer, which is produced by encoding/json for such
+// a span context. The returned span context can be passed to the Inject
+// method of a BridgeTracer or used as a reference when starting a span.
+// An error is returned if the representation is malformed, has an
+// unsupported version or has invalid IDs.
+func ParseSpanContextJSON(data []byte) (ot.SpanContext, error) {
+	var sc spanContextJSON
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return nil, err
+	}
+	if sc.Version != spanContextJSONVersion {
+		return nil, fmt.Errorf("unsupported span context version %d", sc.Version)
+	}
+	traceID, err := otel.TraceIDFromHex(sc.TraceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trace ID %q: %w", sc.TraceID, err)
+	}
+	spanID, err := otel.SpanIDFromHex(sc.SpanID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid span ID %q: %w", sc.SpanID, err)
+	}
+	flags, err := hex.DecodeString(sc.TraceFlags)
+	if err != nil || len(flags) != 1 {
+		return nil, fmt.Errorf("invalid trace flags %q", sc.TraceFlags)
+	}
+	bridgeSC := newBridgeSpanContext(otel.SpanContext{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags[0],
+	}, nil, config{})
+	bridgeSC.remote = true
+	keys := make([]string, 0, len(sc.Baggage))
+	for k := range sc.Baggage {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		entryJSON := sc.Baggage[k]
+		entry := BaggageEntry{Value: entryJSON.Value}
+		for _, p := range entryJSON.Properties {
+			entry.Properties = append(entry.Properties, BaggageProperty(p))
+		}
+		bridgeSC.setExtractedBaggageEntry(k, entry)
+	}
+	return bridgeSC, nil
+}
@@ -0,0 +1,36 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package propagation holds the context values shared by the composite
+// propagator of the otel package and the propagators package.
+package propagation
+
+import "context"
+
+type extractionSourceKeyType struct{}
+
+var extractionSourceKey extractionSourceKeyType
+
+// ContextWithExtractionSource returns a copy of ctx recording source as
+// the propagator that extracted the remote span context of ctx.
+func ContextWithExtractionSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, extractionSourceKey, source)
+}
+
+// ExtractionSource returns the source recorded with
+// ContextWithExtractionSource, or an empty string.
+func ExtractionSource(ctx context.Context) string {
+	source, _ := ctx.Value(extractionSourceKey).(string)
+	return source
+}
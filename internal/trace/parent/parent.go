@@ -22,12 +22,19 @@ import (
 )
 
 func GetSpanContextAndLinks(ctx context.Context, ignoreContext bool) (otel.SpanContext, bool, []otel.Link) {
+	return GetSpanContextAndLinksWith(ctx, ignoreContext, DefaultLinkAttributes)
+}
+
+// GetSpanContextAndLinksWith is like GetSpanContextAndLinks, with the
+// attributes of the links to the demoted parents returned by
+// linkAttributes for the kind of the parent, "current" or "remote".
+func GetSpanContextAndLinksWith(ctx context.Context, ignoreContext bool, linkAttributes func(kind string) []label.KeyValue) (otel.SpanContext, bool, []otel.Link) {
 	lsctx := otel.SpanFromContext(ctx).SpanContext()
 	rsctx := otel.RemoteSpanContextFromContext(ctx)
 
 	if ignoreContext {
-		links := addLinkIfValid(nil, lsctx, "current")
-		links = addLinkIfValid(links, rsctx, "remote")
+		links := addLinkIfValid(nil, lsctx, linkAttributes("current"))
+		links = addLinkIfValid(links, rsctx, linkAttributes("remote"))
 
 		return otel.SpanContext{}, false, links
 	}
@@ -40,14 +47,20 @@ func GetSpanContextAndLinks(ctx context.Context, ignoreContext bool) (otel.SpanC
 	return otel.SpanContext{}, false, nil
 }
 
-func addLinkIfValid(links []otel.Link, sc otel.SpanContext, kind string) []otel.Link {
+// DefaultLinkAttributes returns the ignored-on-demand attribute set to
+// the kind of the demoted parent.
+func DefaultLinkAttributes(kind string) []label.KeyValue {
+	return []label.KeyValue{
+		label.String("ignored-on-demand", kind),
+	}
+}
+
+func addLinkIfValid(links []otel.Link, sc otel.SpanContext, attrs []label.KeyValue) []otel.Link {
 	if !sc.IsValid() {
 		return links
 	}
 	return append(links, otel.Link{
 		SpanContext: sc,
-		Attributes: []label.KeyValue{
-			label.String("ignored-on-demand", kind),
-		},
+		Attributes:  attrs,
 	})
 }
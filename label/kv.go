@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"time"
 )
 
 // KeyValue holds a key and value pair.
@@ -102,11 +103,25 @@ func Array(k string, v interface{}) KeyValue {
 
 // Any creates a new key-value pair instance with a passed name and
 // automatic type inference. This is slower, and not type-safe.
+//
+// A time.Time value is converted to an RFC 3339 string with
+// nanoseconds and a time.Duration value to an int64 number of
+// nanoseconds. Other values implementing fmt.Stringer are converted
+// with their String method. Values of the primitive types and arrays
+// and slices of them keep their type. Other values are converted to
+// their JSON encoding, or formatted with fmt.Sprint if that fails.
 func Any(k string, value interface{}) KeyValue {
 	if value == nil {
 		return String(k, "<nil>")
 	}
 
+	switch v := value.(type) {
+	case time.Time:
+		return String(k, v.Format(time.RFC3339Nano))
+	case time.Duration:
+		return Int64(k, int64(v))
+	}
+
 	if stringer, ok := value.(fmt.Stringer); ok {
 		return String(k, stringer.String())
 	}
@@ -115,7 +130,9 @@ func Any(k string, value interface{}) KeyValue {
 
 	switch rv.Kind() {
 	case reflect.Array, reflect.Slice:
-		return Array(k, value)
+		if kv := Array(k, value); kv.Value.Type() != INVALID {
+			return kv
+		}
 	case reflect.Bool:
 		return Bool(k, rv.Bool())
 	case reflect.Int, reflect.Int8, reflect.Int16:
@@ -17,6 +17,7 @@ package label_test
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -194,6 +195,48 @@ func TestAny(t *testing.T) {
 			wantType:  label.STRING,
 			wantValue: "foo",
 		},
+		{
+			key:       "time.Time serialized as RFC 3339",
+			value:     time.Date(2020, time.October, 16, 12, 30, 0, 500, time.UTC),
+			wantType:  label.STRING,
+			wantValue: "2020-10-16T12:30:00.0000005Z",
+		},
+		{
+			key:       "time.Duration converted to nanoseconds",
+			value:     1500 * time.Millisecond,
+			wantType:  label.INT64,
+			wantValue: int64(1500000000),
+		},
+		{
+			key:       "int type inferred",
+			value:     42,
+			wantType:  label.INT64,
+			wantValue: int64(42),
+		},
+		{
+			key:       "bool type inferred from a named type",
+			value:     namedBool(true),
+			wantType:  label.BOOL,
+			wantValue: true,
+		},
+		{
+			key:       "slice type inferred",
+			value:     []string{"a", "b"},
+			wantType:  label.ARRAY,
+			wantValue: [2]string{"a", "b"},
+		},
+		{
+			key:       "array type inferred",
+			value:     [2]int64{1, 2},
+			wantType:  label.ARRAY,
+			wantValue: [2]int64{1, 2},
+		},
+		{
+			key:       "slice of unsupported type serialized as JSON",
+			value:     []map[string]int{{"a": 1}},
+			wantType:  label.STRING,
+			wantValue: `[{"a":1}]`,
+		},
 		{
 			key:       "unknown value serialized as %v",
 			value:     nil,
@@ -224,3 +267,5 @@ func TestAny(t *testing.T) {
 		}
 	}
 }
+
+type namedBool bool
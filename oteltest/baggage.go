@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oteltest
+
+import (
+	"sort"
+
+	"go.opentelemetry.io/otel/internal/baggage"
+	"go.opentelemetry.io/otel/label"
+)
+
+// OrderedBaggage is a set of baggage key-value pairs iterated in the
+// order of their keys. Unlike a baggage.Map, whose iteration order is
+// random, it lets tests assert on the iterated baggage without
+// depending on map ordering.
+type OrderedBaggage struct {
+	kvs []label.KeyValue
+}
+
+// NewOrderedBaggage returns an OrderedBaggage holding the passed
+// key-value pairs. If a key is passed more than once, the last value is
+// kept.
+func NewOrderedBaggage(kvs ...label.KeyValue) OrderedBaggage {
+	return OrderedBaggageFromMap(baggage.NewMap(baggage.MapUpdate{MultiKV: kvs}))
+}
+
+// OrderedBaggageFromMap returns an OrderedBaggage holding the key-value
+// pairs of m, for example of the baggage a propagator extracted into a
+// context.
+func OrderedBaggageFromMap(m baggage.Map) OrderedBaggage {
+	kvs := make([]label.KeyValue, 0, m.Len())
+	m.Foreach(func(kv label.KeyValue) bool {
+		kvs = append(kvs, kv)
+		return true
+	})
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return OrderedBaggage{kvs: kvs}
+}
+
+// Foreach calls f once on each key-value pair in the order of their
+// keys until all the pairs were iterated or f returns false.
+func (b OrderedBaggage) Foreach(f func(label.KeyValue) bool) {
+	for _, kv := range b.kvs {
+		if !f(kv) {
+			return
+		}
+	}
+}
+
+// KeyValues returns the key-value pairs of b in the order of their
+// keys.
+func (b OrderedBaggage) KeyValues() []label.KeyValue {
+	kvs := make([]label.KeyValue, len(b.kvs))
+	copy(kvs, b.kvs)
+	return kvs
+}
+
+// Len returns the number of key-value pairs in b.
+func (b OrderedBaggage) Len() int {
+	return len(b.kvs)
+}
+
+// Map returns a baggage.Map holding the key-value pairs of b, to be put
+// in a context with baggage.ContextWithMap.
+func (b OrderedBaggage) Map() baggage.Map {
+	return baggage.NewMap(baggage.MapUpdate{MultiKV: b.kvs})
+}
@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oteltest_test
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/internal/baggage"
+	"go.opentelemetry.io/otel/internal/matchers"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestOrderedBaggage(t *testing.T) {
+	t.Run("iterates in key order", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		for i := 0; i < 10; i++ {
+			b := oteltest.NewOrderedBaggage(
+				label.String("c", "3"),
+				label.String("a", "1"),
+				label.String("d", "4"),
+				label.String("b", "2"),
+			)
+			var got []label.KeyValue
+			b.Foreach(func(kv label.KeyValue) bool {
+				got = append(got, kv)
+				return true
+			})
+			e.Expect(got).ToEqual([]label.KeyValue{
+				label.String("a", "1"),
+				label.String("b", "2"),
+				label.String("c", "3"),
+				label.String("d", "4"),
+			})
+		}
+	})
+
+	t.Run("stops when the callback returns false", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		b := oteltest.NewOrderedBaggage(label.String("b", "2"), label.String("a", "1"))
+		var got []label.Key
+		b.Foreach(func(kv label.KeyValue) bool {
+			got = append(got, kv.Key)
+			return false
+		})
+		e.Expect(got).ToEqual([]label.Key{"a"})
+	})
+
+	t.Run("keeps the last value of a key", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		b := oteltest.NewOrderedBaggage(label.String("a", "1"), label.String("a", "2"))
+		e.Expect(b.KeyValues()).ToEqual([]label.KeyValue{label.String("a", "2")})
+	})
+
+	t.Run("from and to a map", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		m := baggage.NewMap(baggage.MapUpdate{MultiKV: []label.KeyValue{
+			label.String("y", "2"),
+			label.String("x", "1"),
+		}})
+		b := oteltest.OrderedBaggageFromMap(m)
+		e.Expect(b.Len()).ToEqual(2)
+		e.Expect(b.KeyValues()).ToEqual([]label.KeyValue{label.String("x", "1"), label.String("y", "2")})
+		e.Expect(oteltest.OrderedBaggageFromMap(b.Map()).KeyValues()).ToEqual(b.KeyValues())
+	})
+}
@@ -17,10 +17,15 @@ package oteltest
 import (
 	"context"
 	"encoding/binary"
+	"hash/fnv"
+	"strconv"
 	"sync"
 	"sync/atomic"
+	"testing"
+	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
 )
 
 // defaultSpanContextFunc returns the default SpanContextFunc.
@@ -40,13 +45,73 @@ func defaultSpanContextFunc() func(context.Context) otel.SpanContext {
 	}
 }
 
+// nameBasedSpanContextFunc returns a function deriving the IDs of a
+// SpanContext from the name of the new span. The n-th span with a name
+// gets the IDs hashed from the name and n, so the IDs do not depend on
+// the spans with other names.
+func nameBasedSpanContextFunc() func(context.Context, string) otel.SpanContext {
+	var mu sync.Mutex
+	counts := make(map[string]uint64)
+	return func(ctx context.Context, name string) otel.SpanContext {
+		mu.Lock()
+		counts[name]++
+		seed := name + "#" + strconv.FormatUint(counts[name], 10)
+		mu.Unlock()
+
+		var sc otel.SpanContext
+		if lsc := otel.SpanFromContext(ctx).SpanContext(); lsc.IsValid() {
+			sc = lsc
+		} else if rsc := otel.RemoteSpanContextFromContext(ctx); rsc.IsValid() {
+			sc = rsc
+		} else {
+			h := fnv.New128a()
+			_, _ = h.Write([]byte(seed))
+			copy(sc.TraceID[:], h.Sum(nil))
+		}
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(seed))
+		copy(sc.SpanID[:], h.Sum(nil))
+		return sc
+	}
+}
+
 type config struct {
 	// SpanContextFunc returns a SpanContext from an parent Context for a
 	// new span.
 	SpanContextFunc func(context.Context) otel.SpanContext
 
+	// NameSpanContextFunc returns a SpanContext from an parent Context
+	// and the name of a new span. It takes precedence over
+	// SpanContextFunc if set.
+	NameSpanContextFunc func(context.Context, string) otel.SpanContext
+
 	// SpanRecorder keeps track of spans.
 	SpanRecorder SpanRecorder
+
+	// AttributeValueLengthLimit is the maximum number of runes of a
+	// string attribute value. Zero means no limit.
+	AttributeValueLengthLimit int
+
+	// KindDefaultAttributes are the attributes set on a new span of
+	// the kind before the attributes passed when starting it.
+	KindDefaultAttributes map[otel.SpanKind][]label.KeyValue
+
+	// ResourceAttributes are the attributes describing the entity
+	// producing the spans, kept apart from the span attributes.
+	ResourceAttributes []label.KeyValue
+
+	// ClockOffset is added to the wall-clock time used as the start
+	// and end time of a span.
+	ClockOffset time.Duration
+
+	// DemotedParentLinkAttributes returns the attributes of the link to
+	// a parent demoted by otel.WithNewRoot from the reason of the
+	// demotion. Nil means the ignored-on-demand attribute.
+	DemotedParentLinkAttributes func(reason string) []label.KeyValue
+
+	// StrictMode makes the Spans panic when they are used after they
+	// ended.
+	StrictMode bool
 }
 
 func newConfig(opts ...Option) config {
@@ -60,6 +125,11 @@ func newConfig(opts ...Option) config {
 	return conf
 }
 
+// now returns the current time of the clock of c.
+func (c *config) now() time.Time {
+	return time.Now().Add(c.ClockOffset)
+}
+
 // Option applies an option to a config.
 type Option interface {
 	Apply(*config)
@@ -79,6 +149,118 @@ func WithSpanContextFunc(f func(context.Context) otel.SpanContext) Option {
 	return spanContextFuncOption{f}
 }
 
+type nameBasedIDsOption struct{}
+
+func (nameBasedIDsOption) Apply(c *config) {
+	c.NameSpanContextFunc = nameBasedSpanContextFunc()
+}
+
+// WithNameBasedIDs makes the TracerProvider derive the IDs of new
+// Spans from their names. The span ID of the n-th Span with a given
+// name is a hash of the name and n, and so is the trace ID of a Span
+// without a parent. The IDs are stable across test runs as long as
+// the Spans with the same name are started in the same order, which
+// makes them suitable for golden files. This option overrides
+// WithSpanContextFunc.
+func WithNameBasedIDs() Option {
+	return nameBasedIDsOption{}
+}
+
+type attributeValueLengthLimitOption int
+
+func (o attributeValueLengthLimitOption) Apply(c *config) {
+	c.AttributeValueLengthLimit = int(o)
+}
+
+// WithAttributeValueLengthLimit makes the Spans truncate the string
+// and string array attribute values set on them or on their events to
+// limit runes, like SDKs do with overly long values. The number of
+// bytes cut off is available from the DroppedAttributeBytes method of
+// a Span. A non-positive limit disables the truncation, which is the
+// default.
+func WithAttributeValueLengthLimit(limit int) Option {
+	return attributeValueLengthLimitOption(limit)
+}
+
+type kindDefaultAttributesOption map[otel.SpanKind][]label.KeyValue
+
+func (o kindDefaultAttributesOption) Apply(c *config) {
+	c.KindDefaultAttributes = make(map[otel.SpanKind][]label.KeyValue, len(o))
+	for kind, attrs := range o {
+		c.KindDefaultAttributes[kind] = append([]label.KeyValue(nil), attrs...)
+	}
+}
+
+// WithKindDefaultAttributes sets attributes every new Span of a kind
+// starts with, for example to mimic the baseline attributes server and
+// client instrumentation attaches. The kind of a Span is the one passed
+// with otel.WithSpanKind. The attributes passed when starting a Span
+// override the defaults with the same key.
+func WithKindDefaultAttributes(defaults map[otel.SpanKind][]label.KeyValue) Option {
+	return kindDefaultAttributesOption(defaults)
+}
+
+type resourceAttributesOption []label.KeyValue
+
+func (o resourceAttributesOption) Apply(c *config) {
+	c.ResourceAttributes = append([]label.KeyValue(nil), o...)
+}
+
+// WithResourceAttributes sets the attributes of the resource producing
+// the Spans, like service.name. They are returned by the
+// ResourceAttributes method of every Span and never mixed with the
+// attributes of the Span, so a test can check at which level
+// instrumentation records some data.
+func WithResourceAttributes(attrs ...label.KeyValue) Option {
+	return resourceAttributesOption(attrs)
+}
+
+type clockOffsetOption time.Duration
+
+func (o clockOffsetOption) Apply(c *config) {
+	c.ClockOffset = time.Duration(o)
+}
+
+// WithClockOffset makes the Spans of the TracerProvider use the
+// wall-clock time shifted by d as their start and end time. Using two
+// TracerProviders with different offsets simulates the clock skew
+// between two services, for example to test instrumentation computing
+// durations across services. The timestamps passed explicitly with
+// otel.WithTimestamp are used as is.
+func WithClockOffset(d time.Duration) Option {
+	return clockOffsetOption(d)
+}
+
+type demotedParentLinkAttributesOption func(reason string) []label.KeyValue
+
+func (o demotedParentLinkAttributesOption) Apply(c *config) {
+	c.DemotedParentLinkAttributes = o
+}
+
+// WithDemotedParentLinkAttributes sets the function returning the
+// attributes of the link to a parent demoted by otel.WithNewRoot, from
+// the reason of the demotion, DemotedCurrent or DemotedRemote. By
+// default the link has the "ignored-on-demand" attribute set to the
+// reason.
+func WithDemotedParentLinkAttributes(f func(reason string) []label.KeyValue) Option {
+	return demotedParentLinkAttributesOption(f)
+}
+
+type strictModeOption bool
+
+func (o strictModeOption) Apply(c *config) {
+	c.StrictMode = bool(o)
+}
+
+// WithStrictMode makes the Spans of the TracerProvider panic on the
+// known misuses of the API that are silently ignored otherwise: ending
+// a span twice, or setting its attributes, name or status, adding
+// events or links to it or recording errors on it after it ended. This
+// makes instrumentation bugs fail the tests of the instrumentation.
+func WithStrictMode() Option {
+	return strictModeOption(true)
+}
+
 type spanRecorderOption struct {
 	SpanRecorder SpanRecorder
 }
@@ -101,6 +283,24 @@ type SpanRecorder interface {
 	OnEnd(span *Span)
 }
 
+// LinkRecorder is implemented by a SpanRecorder that wants to know about
+// the links added to a Span with AddLink.
+type LinkRecorder interface {
+	// OnAddLink is called by the Span when a link is added to it.
+	OnAddLink(span *Span, link otel.Link)
+}
+
+// AttributeRecorder is implemented by a SpanRecorder that wants to know
+// about every change of the attributes of a Span, for example to check
+// that instrumentation does not set the same attributes repeatedly.
+type AttributeRecorder interface {
+	// OnSetAttributes is called by the Span with the attributes passed
+	// to its SetAttributes method, after the attribute value length
+	// limit is applied. It is called for the attributes set when the
+	// Span is started too, before OnStart.
+	OnSetAttributes(span *Span, attrs []label.KeyValue)
+}
+
 // StandardSpanRecorder is a SpanRecorder that records all started and ended
 // spans in an ordered recording. StandardSpanRecorder is designed to be
 // concurrent safe and can by used by multiple goroutines.
@@ -127,6 +327,7 @@ func (ssr *StandardSpanRecorder) OnEnd(span *Span) {
 }
 
 // Started returns a copy of all started Spans in the order they were started.
+// The result can be iterated while other goroutines start and end Spans.
 func (ssr *StandardSpanRecorder) Started() []*Span {
 	ssr.startedMu.RLock()
 	defer ssr.startedMu.RUnlock()
@@ -138,6 +339,7 @@ func (ssr *StandardSpanRecorder) Started() []*Span {
 }
 
 // Completed returns a copy of all ended Spans in the order they were ended.
+// The result can be iterated while other goroutines start and end Spans.
 func (ssr *StandardSpanRecorder) Completed() []*Span {
 	ssr.doneMu.RLock()
 	defer ssr.doneMu.RUnlock()
@@ -147,3 +349,146 @@ func (ssr *StandardSpanRecorder) Completed() []*Span {
 	}
 	return done
 }
+
+// StartedFor returns the started Spans created by the Tracers with the
+// passed instrumentation name, in the order they were started.
+func (ssr *StandardSpanRecorder) StartedFor(instrumentationName string) []*Span {
+	return spansFor(ssr.Started(), instrumentationName)
+}
+
+// CompletedFor returns the ended Spans created by the Tracers with the
+// passed instrumentation name, in the order they were ended.
+func (ssr *StandardSpanRecorder) CompletedFor(instrumentationName string) []*Span {
+	return spansFor(ssr.Completed(), instrumentationName)
+}
+
+// RecorderSnapshot is the state of a StandardSpanRecorder at a point
+// in time. It holds Snapshots of the spans, so it does not change
+// when the spans or the recorder do.
+type RecorderSnapshot struct {
+	// Started are the spans started so far, in the order they were
+	// started.
+	Started []Snapshot
+	// Completed are the spans ended so far, in the order they were
+	// ended.
+	Completed []Snapshot
+}
+
+// Snapshot returns the current state of ssr. Comparing it to a later
+// snapshot with the Diff method tells which spans a block of code
+// produced.
+func (ssr *StandardSpanRecorder) Snapshot() RecorderSnapshot {
+	return RecorderSnapshot{
+		Started:   snapshots(ssr.Started()),
+		Completed: snapshots(ssr.Completed()),
+	}
+}
+
+func snapshots(spans []*Span) []Snapshot {
+	s := make([]Snapshot, len(spans))
+	for i, span := range spans {
+		s[i] = span.Snapshot()
+	}
+	return s
+}
+
+// Diff returns the spans started and ended between rs and later, which
+// must be a snapshot of the same StandardSpanRecorder taken after rs.
+// A span started before rs and ended after it is only in the Completed
+// spans of the result.
+func (rs RecorderSnapshot) Diff(later RecorderSnapshot) RecorderSnapshot {
+	return RecorderSnapshot{
+		Started:   addedSnapshots(rs.Started, later.Started),
+		Completed: addedSnapshots(rs.Completed, later.Completed),
+	}
+}
+
+// addedSnapshots returns the snapshots of later past the ones of
+// earlier. StandardSpanRecorder only appends spans, so the spans of an
+// earlier snapshot are a prefix of the ones of a later snapshot.
+func addedSnapshots(earlier, later []Snapshot) []Snapshot {
+	if len(later) <= len(earlier) {
+		return nil
+	}
+	return append([]Snapshot{}, later[len(earlier):]...)
+}
+
+// Unfinished returns the started Spans that have not ended, in the
+// order they were started.
+func (ssr *StandardSpanRecorder) Unfinished() []*Span {
+	var unfinished []*Span
+	for _, s := range ssr.Started() {
+		s.lock.RLock()
+		ended := s.ended
+		s.lock.RUnlock()
+		if !ended {
+			unfinished = append(unfinished, s)
+		}
+	}
+	return unfinished
+}
+
+// AssertNoLeaks reports an error to t for every started Span that has
+// not ended, which catches instrumentation forgetting to end its
+// spans. It is usually called at the end of a test, or deferred.
+func (ssr *StandardSpanRecorder) AssertNoLeaks(t testing.TB) {
+	t.Helper()
+	for _, s := range ssr.Unfinished() {
+		t.Errorf("span %q (span ID %s) was started but never ended", s.Name(), s.SpanContext().SpanID)
+	}
+}
+
+// AssertStartOrder reports an error to t unless the started Spans have
+// exactly the passed names, in the order they were started. It
+// verifies that instrumentation starts its spans in the expected
+// order.
+func (ssr *StandardSpanRecorder) AssertStartOrder(t testing.TB, names ...string) {
+	t.Helper()
+	started := startedNames(ssr.Started())
+	if len(started) != len(names) {
+		t.Errorf("got spans %q started, want %q", started, names)
+		return
+	}
+	for i := range names {
+		if started[i] != names[i] {
+			t.Errorf("got spans %q started, want %q", started, names)
+			return
+		}
+	}
+}
+
+// AssertStartOrderInterleaved works like AssertStartOrder, but other
+// Spans may have been started before, after and between the Spans with
+// the passed names. It verifies the relative order of some spans when
+// unrelated instrumentation also starts spans.
+func (ssr *StandardSpanRecorder) AssertStartOrderInterleaved(t testing.TB, names ...string) {
+	t.Helper()
+	started := startedNames(ssr.Started())
+	next := 0
+	for _, name := range started {
+		if next < len(names) && name == names[next] {
+			next++
+		}
+	}
+	if next < len(names) {
+		t.Errorf("got spans %q started, want %q in this order, missing %q after %q", started, names, names[next], names[:next])
+	}
+}
+
+func startedNames(spans []*Span) []string {
+	names := make([]string, 0, len(spans))
+	for _, s := range spans {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+func spansFor(spans []*Span, instrumentationName string) []*Span {
+	var filtered []*Span
+	for _, s := range spans {
+		if s.tracer.Name == instrumentationName {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
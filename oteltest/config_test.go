@@ -0,0 +1,287 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oteltest_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/internal/matchers"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestStandardSpanRecorder(t *testing.T) {
+	t.Run("#Started concurrently", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		sr := new(oteltest.StandardSpanRecorder)
+		tracer := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)).Tracer(t.Name())
+
+		const goroutines, spans = 4, 100
+		var wg sync.WaitGroup
+		for i := 0; i < goroutines; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < spans; j++ {
+					_, span := tracer.Start(context.Background(), "span")
+					span.SetAttributes(label.Int("index", j))
+					span.AddEvent("event")
+					span.End()
+				}
+			}()
+		}
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		for running := true; running; {
+			select {
+			case <-done:
+				running = false
+			default:
+			}
+			for _, s := range sr.Started() {
+				_ = s.Name()
+				_ = s.Ended()
+				_, _ = s.EndTime()
+				_ = s.Events()
+				_ = s.Attributes()
+			}
+			for _, s := range sr.Completed() {
+				_ = s.StatusCode()
+			}
+		}
+
+		e.Expect(len(sr.Started())).ToEqual(goroutines * spans)
+		e.Expect(len(sr.Completed())).ToEqual(goroutines * spans)
+	})
+
+	t.Run("#OnEnd reads the span", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		sr := &endedRecorder{}
+		_, span := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)).Tracer(t.Name()).Start(context.Background(), "read")
+		span.End()
+
+		e.Expect(sr.ended).ToEqual([]string{"read"})
+	})
+
+	t.Run("#StartedFor", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		sr := new(oteltest.StandardSpanRecorder)
+		tp := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+		_, http1 := tp.Tracer("http").Start(context.Background(), "http1")
+		_, db := tp.Tracer("db").Start(context.Background(), "db")
+		_, http2 := tp.Tracer("http").Start(context.Background(), "http2")
+
+		e.Expect(sr.StartedFor("http")).ToEqual([]*oteltest.Span{http1.(*oteltest.Span), http2.(*oteltest.Span)})
+		e.Expect(sr.StartedFor("db")).ToEqual([]*oteltest.Span{db.(*oteltest.Span)})
+		e.Expect(len(sr.StartedFor("grpc"))).ToEqual(0)
+	})
+
+	t.Run("#CompletedFor", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		sr := new(oteltest.StandardSpanRecorder)
+		tp := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+		_, http1 := tp.Tracer("http").Start(context.Background(), "http1")
+		_, db := tp.Tracer("db").Start(context.Background(), "db")
+		_, http2 := tp.Tracer("http").Start(context.Background(), "http2")
+		http2.End()
+		db.End()
+
+		e.Expect(sr.CompletedFor("http")).ToEqual([]*oteltest.Span{http2.(*oteltest.Span)})
+		e.Expect(sr.CompletedFor("db")).ToEqual([]*oteltest.Span{db.(*oteltest.Span)})
+
+		http1.End()
+		e.Expect(sr.CompletedFor("http")).ToEqual([]*oteltest.Span{http2.(*oteltest.Span), http1.(*oteltest.Span)})
+	})
+	t.Run("#Snapshot", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		sr := new(oteltest.StandardSpanRecorder)
+		tracer := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)).Tracer(t.Name())
+		_, before := tracer.Start(context.Background(), "before")
+		_, ended := tracer.Start(context.Background(), "ended")
+		ended.End()
+
+		snapshot := sr.Snapshot()
+		e.Expect(len(snapshot.Started)).ToEqual(2)
+		e.Expect(len(snapshot.Completed)).ToEqual(1)
+
+		before.SetName("renamed")
+		_, during := tracer.Start(context.Background(), "during")
+		during.End()
+		before.End()
+
+		e.Expect(snapshot.Started[0].Name).ToEqual("before")
+		e.Expect(snapshot.Started[0].Ended).ToBeFalse()
+		e.Expect(len(snapshot.Started)).ToEqual(2)
+		e.Expect(len(snapshot.Completed)).ToEqual(1)
+	})
+
+	t.Run("#Diff", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		sr := new(oteltest.StandardSpanRecorder)
+		tracer := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)).Tracer(t.Name())
+		_, before := tracer.Start(context.Background(), "before")
+		_, ended := tracer.Start(context.Background(), "ended")
+		ended.End()
+
+		snapshot := sr.Snapshot()
+		_, during := tracer.Start(context.Background(), "during")
+		during.End()
+		before.End()
+		diff := snapshot.Diff(sr.Snapshot())
+
+		e.Expect(names(diff.Started)).ToEqual([]string{"during"})
+		e.Expect(names(diff.Completed)).ToEqual([]string{"during", "before"})
+
+		empty := snapshot.Diff(snapshot)
+		e.Expect(len(empty.Started)).ToEqual(0)
+		e.Expect(len(empty.Completed)).ToEqual(0)
+	})
+	t.Run("#Unfinished", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		sr := new(oteltest.StandardSpanRecorder)
+		tracer := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)).Tracer(t.Name())
+		_, leaked := tracer.Start(context.Background(), "leaked")
+		_, ended := tracer.Start(context.Background(), "ended")
+		ended.End()
+
+		e.Expect(sr.Unfinished()).ToEqual([]*oteltest.Span{leaked.(*oteltest.Span)})
+
+		leaked.End()
+		e.Expect(len(sr.Unfinished())).ToEqual(0)
+	})
+
+	t.Run("#AssertNoLeaks", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		sr := new(oteltest.StandardSpanRecorder)
+		tracer := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)).Tracer(t.Name())
+		_, leaked := tracer.Start(context.Background(), "leaked")
+		_, ended := tracer.Start(context.Background(), "ended")
+		ended.End()
+
+		tb := &fakeTB{}
+		sr.AssertNoLeaks(tb)
+		e.Expect(tb.errors).ToEqual([]string{
+			fmt.Sprintf("span %q (span ID %s) was started but never ended", "leaked", leaked.SpanContext().SpanID),
+		})
+
+		leaked.End()
+		tb = &fakeTB{}
+		sr.AssertNoLeaks(tb)
+		e.Expect(len(tb.errors)).ToEqual(0)
+	})
+
+	t.Run("#AssertStartOrder", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		sr := new(oteltest.StandardSpanRecorder)
+		tracer := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)).Tracer(t.Name())
+		ctx, _ := tracer.Start(context.Background(), "parent")
+		tracer.Start(ctx, "child")
+		tracer.Start(context.Background(), "sibling")
+
+		tb := &fakeTB{}
+		sr.AssertStartOrder(tb, "parent", "child", "sibling")
+		e.Expect(len(tb.errors)).ToEqual(0)
+
+		for _, want := range [][]string{
+			{"child", "parent", "sibling"},
+			{"parent", "child"},
+			{"parent", "child", "sibling", "extra"},
+		} {
+			tb = &fakeTB{}
+			sr.AssertStartOrder(tb, want...)
+			e.Expect(tb.errors).ToEqual([]string{
+				fmt.Sprintf("got spans %q started, want %q", []string{"parent", "child", "sibling"}, want),
+			})
+		}
+	})
+
+	t.Run("#AssertStartOrderInterleaved", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		sr := new(oteltest.StandardSpanRecorder)
+		tracer := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)).Tracer(t.Name())
+		for _, name := range []string{"a", "x", "b", "y", "c"} {
+			tracer.Start(context.Background(), name)
+		}
+
+		for _, want := range [][]string{
+			{"a", "b", "c"},
+			{"x", "y"},
+			{"a", "x", "b", "y", "c"},
+			{},
+		} {
+			tb := &fakeTB{}
+			sr.AssertStartOrderInterleaved(tb, want...)
+			e.Expect(len(tb.errors)).ToEqual(0)
+		}
+
+		tb := &fakeTB{}
+		sr.AssertStartOrderInterleaved(tb, "a", "c", "b")
+		e.Expect(tb.errors).ToEqual([]string{
+			fmt.Sprintf("got spans %q started, want %q in this order, missing %q after %q",
+				[]string{"a", "x", "b", "y", "c"}, []string{"a", "c", "b"}, "b", []string{"a", "c"}),
+		})
+	})
+}
+
+func names(snapshots []oteltest.Snapshot) []string {
+	var n []string
+	for _, s := range snapshots {
+		n = append(n, s.Name)
+	}
+	return n
+}
+
+// endedRecorder records the names of the ended spans, read from the
+// spans when they end.
+type endedRecorder struct {
+	ended []string
+}
+
+func (r *endedRecorder) OnStart(*oteltest.Span) {}
+
+func (r *endedRecorder) OnEnd(span *oteltest.Span) {
+	if span.Ended() {
+		r.ended = append(r.ended, span.Name())
+	}
+}
+
+// fakeTB records the errors reported to it.
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
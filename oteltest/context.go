@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oteltest
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+)
+
+// ContextWithRemoteParent returns a copy of ctx with a remote span
+// context built from the passed hex encoded IDs, like the one a
+// propagator extracts for a server. Spans started with the returned
+// context are its children, which makes it handy to test server side
+// instrumentation. An error is returned, along with ctx, if an ID is
+// not a valid lowercase hex encoded ID.
+func ContextWithRemoteParent(ctx context.Context, traceIDHex, spanIDHex string, sampled bool) (context.Context, error) {
+	traceID, err := otel.TraceIDFromHex(traceIDHex)
+	if err != nil {
+		return ctx, err
+	}
+	spanID, err := otel.SpanIDFromHex(spanIDHex)
+	if err != nil {
+		return ctx, err
+	}
+	sc := otel.SpanContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}
+	if sampled {
+		sc.TraceFlags = otel.FlagsSampled
+	}
+	return otel.ContextWithRemoteSpanContext(ctx, sc), nil
+}
@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oteltest_test
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/internal/matchers"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestContextWithRemoteParent(t *testing.T) {
+	t.Run("starts children of the remote parent", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		ctx, err := oteltest.ContextWithRemoteParent(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true)
+		e.Expect(err).ToBeNil()
+
+		parent := otel.RemoteSpanContextFromContext(ctx)
+		e.Expect(parent.TraceID.String()).ToEqual("4bf92f3577b34da6a3ce929d0e0e4736")
+		e.Expect(parent.SpanID.String()).ToEqual("00f067aa0ba902b7")
+		e.Expect(parent.IsSampled()).ToBeTrue()
+
+		_, span := oteltest.NewTracerProvider().Tracer(t.Name()).Start(ctx, "server", otel.WithSpanKind(otel.SpanKindServer))
+		child, ok := span.(*oteltest.Span)
+		e.Expect(ok).ToBeTrue()
+		e.Expect(child.IsChildOf(parent)).ToBeTrue()
+	})
+
+	t.Run("sets the sampled flag only if sampled", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		ctx, err := oteltest.ContextWithRemoteParent(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", false)
+		e.Expect(err).ToBeNil()
+		e.Expect(otel.RemoteSpanContextFromContext(ctx).IsSampled()).ToBeFalse()
+	})
+
+	t.Run("rejects invalid IDs", func(t *testing.T) {
+		for _, ids := range [][2]string{
+			{"4BF92F3577B34DA6A3CE929D0E0E4736", "00f067aa0ba902b7"},
+			{"4bf92f3577b34da6a3ce929d0e0e47", "00f067aa0ba902b7"},
+			{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902"},
+			{"00000000000000000000000000000000", "00f067aa0ba902b7"},
+			{"4bf92f3577b34da6a3ce929d0e0e4736", "0000000000000000"},
+		} {
+			e := matchers.NewExpecter(t)
+
+			parent := context.Background()
+			ctx, err := oteltest.ContextWithRemoteParent(parent, ids[0], ids[1], true)
+			e.Expect(err).NotToBeNil()
+			e.Expect(ctx == parent).ToBeTrue()
+		}
+	})
+}
@@ -16,6 +16,7 @@ package oteltest
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"go.opentelemetry.io/otel"
 )
@@ -29,6 +30,9 @@ type TracerProvider struct {
 
 	tracersMu sync.Mutex
 	tracers   map[instrumentation]*Tracer
+
+	// isShutdown is set to 1 by Shutdown, it is accessed atomically.
+	isShutdown int32
 }
 
 var _ otel.TracerProvider = (*TracerProvider)(nil)
@@ -45,7 +49,9 @@ type instrumentation struct {
 	Name, Version string
 }
 
-// Tracer returns an OpenTelemetry Tracer used for testing.
+// Tracer returns an OpenTelemetry Tracer used for testing. Repeated calls
+// with the same instrumentation name and version return the same Tracer.
+// It is safe to call Tracer concurrently.
 func (p *TracerProvider) Tracer(instName string, opts ...otel.TracerOption) otel.Tracer {
 	conf := otel.NewTracerConfig(opts...)
 
@@ -58,11 +64,23 @@ func (p *TracerProvider) Tracer(instName string, opts ...otel.TracerOption) otel
 	t, ok := p.tracers[inst]
 	if !ok {
 		t = &Tracer{
-			Name:    instName,
-			Version: conf.InstrumentationVersion,
-			config:  &p.config,
+			Name:     instName,
+			Version:  conf.InstrumentationVersion,
+			config:   &p.config,
+			provider: p,
 		}
 		p.tracers[inst] = t
 	}
 	return t
 }
+
+// Shutdown shuts p down. Spans started by the Tracers of p after
+// Shutdown is called are not recording and are not passed to the
+// SpanRecorder.
+func (p *TracerProvider) Shutdown() {
+	atomic.StoreInt32(&p.isShutdown, 1)
+}
+
+func (p *TracerProvider) shutdown() bool {
+	return atomic.LoadInt32(&p.isShutdown) != 0
+}
@@ -0,0 +1,206 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oteltest_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/internal/matchers"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestTracerProvider(t *testing.T) {
+	t.Run("#Tracer", func(t *testing.T) {
+		t.Run("returns the same tracer for the same name and version", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			tp := oteltest.NewTracerProvider()
+			tracer := tp.Tracer("test", otel.WithInstrumentationVersion("v1"))
+
+			e.Expect(tp.Tracer("test", otel.WithInstrumentationVersion("v1")) == tracer).ToBeTrue()
+			e.Expect(tp.Tracer("test", otel.WithInstrumentationVersion("v2")) == tracer).ToBeFalse()
+			e.Expect(tp.Tracer("other", otel.WithInstrumentationVersion("v1")) == tracer).ToBeFalse()
+		})
+
+		t.Run("returns the same tracer when called concurrently", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			tp := oteltest.NewTracerProvider()
+			tracers := make([]otel.Tracer, 16)
+			var wg sync.WaitGroup
+			for i := range tracers {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					tracers[i] = tp.Tracer("test")
+				}(i)
+			}
+			wg.Wait()
+
+			for _, tracer := range tracers {
+				e.Expect(tracer == tracers[0]).ToBeTrue()
+			}
+		})
+	})
+
+	t.Run("WithNameBasedIDs", func(t *testing.T) {
+		t.Run("derives stable IDs from span names", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			start := func() []otel.SpanContext {
+				tracer := oteltest.NewTracerProvider(oteltest.WithNameBasedIDs()).Tracer(t.Name())
+				ctx, parent := tracer.Start(context.Background(), "parent")
+				_, child := tracer.Start(ctx, "child")
+				_, again := tracer.Start(ctx, "child")
+				return []otel.SpanContext{parent.SpanContext(), child.SpanContext(), again.SpanContext()}
+			}
+
+			first := start()
+			e.Expect(start()).ToEqual(first)
+
+			e.Expect(first[0].IsValid()).ToBeTrue()
+			e.Expect(first[1].TraceID).ToEqual(first[0].TraceID)
+			e.Expect(first[2].TraceID).ToEqual(first[0].TraceID)
+			e.Expect(first[1].SpanID).NotToEqual(first[0].SpanID)
+			e.Expect(first[2].SpanID).NotToEqual(first[1].SpanID)
+		})
+
+		t.Run("does not depend on spans with other names", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			tracer := oteltest.NewTracerProvider(oteltest.WithNameBasedIDs()).Tracer(t.Name())
+			_, span := tracer.Start(context.Background(), "span")
+
+			tracer = oteltest.NewTracerProvider(oteltest.WithNameBasedIDs()).Tracer(t.Name())
+			tracer.Start(context.Background(), "other")
+			_, sameName := tracer.Start(context.Background(), "span")
+
+			e.Expect(sameName.SpanContext()).ToEqual(span.SpanContext())
+		})
+	})
+	t.Run("WithKindDefaultAttributes", func(t *testing.T) {
+		tp := oteltest.NewTracerProvider(oteltest.WithKindDefaultAttributes(map[otel.SpanKind][]label.KeyValue{
+			otel.SpanKindServer: {label.String("role", "server"), label.Bool("default", true)},
+			otel.SpanKindClient: {label.String("role", "client")},
+		}))
+
+		for _, tc := range []struct {
+			kind otel.SpanKind
+			want map[label.Key]label.Value
+		}{
+			{
+				kind: otel.SpanKindServer,
+				want: map[label.Key]label.Value{
+					"role":    label.StringValue("server"),
+					"default": label.BoolValue(false),
+				},
+			},
+			{
+				kind: otel.SpanKindClient,
+				want: map[label.Key]label.Value{
+					"role":    label.StringValue("client"),
+					"default": label.BoolValue(false),
+				},
+			},
+			{
+				kind: otel.SpanKindInternal,
+				want: map[label.Key]label.Value{
+					"default": label.BoolValue(false),
+				},
+			},
+		} {
+			tc := tc
+			t.Run("merges the defaults of "+tc.kind.String()+" spans", func(t *testing.T) {
+				t.Parallel()
+
+				e := matchers.NewExpecter(t)
+
+				_, span := tp.Tracer(t.Name()).Start(context.Background(), "test",
+					otel.WithSpanKind(tc.kind),
+					otel.WithAttributes(label.Bool("default", false)),
+				)
+				e.Expect(span.(*oteltest.Span).Attributes()).ToEqual(tc.want)
+			})
+		}
+	})
+	t.Run("WithResourceAttributes", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		tp := oteltest.NewTracerProvider(oteltest.WithResourceAttributes(
+			label.String("service.name", "checkout"),
+			label.String("service.version", "1"),
+			label.String("service.version", "2"),
+		))
+		_, span := tp.Tracer(t.Name()).Start(context.Background(), "test",
+			otel.WithAttributes(label.String("http.method", "GET")),
+		)
+		span.SetAttributes(label.String("service.name", "span-level"))
+		got := span.(*oteltest.Span)
+
+		e.Expect(got.ResourceAttributes()).ToEqual(map[label.Key]label.Value{
+			"service.name":    label.StringValue("checkout"),
+			"service.version": label.StringValue("2"),
+		})
+		e.Expect(got.Attributes()).ToEqual(map[label.Key]label.Value{
+			"http.method":  label.StringValue("GET"),
+			"service.name": label.StringValue("span-level"),
+		})
+		e.Expect(got.Snapshot().ResourceAttributes).ToEqual(got.ResourceAttributes())
+
+		_, other := oteltest.NewTracerProvider().Tracer(t.Name()).Start(context.Background(), "test")
+		e.Expect(len(other.(*oteltest.Span).ResourceAttributes())).ToEqual(0)
+		diff := oteltest.DiffSpans(other.(*oteltest.Span).Snapshot(), got.Snapshot())
+		e.Expect(strings.Split(diff, "\n")).ToContain(
+			`resource attribute "service.name": added "checkout"`,
+		)
+	})
+	t.Run("WithClockOffset", func(t *testing.T) {
+		e := matchers.NewExpecter(t)
+
+		const skew = time.Hour
+		before := time.Now()
+		_, ahead := oteltest.NewTracerProvider(oteltest.WithClockOffset(skew)).Tracer(t.Name()).Start(context.Background(), "ahead")
+		_, behind := oteltest.NewTracerProvider(oteltest.WithClockOffset(-skew)).Tracer(t.Name()).Start(context.Background(), "behind")
+		ahead.End()
+		behind.End()
+		after := time.Now()
+
+		aheadSpan, behindSpan := ahead.(*oteltest.Span), behind.(*oteltest.Span)
+		e.Expect(aheadSpan.StartTime()).ToBeTemporally(matchers.AfterOrSameTime, before.Add(skew))
+		e.Expect(behindSpan.StartTime()).ToBeTemporally(matchers.BeforeOrSameTime, after.Add(-skew))
+		aheadEnd, _ := aheadSpan.EndTime()
+		e.Expect(aheadEnd).ToBeTemporally(matchers.BeforeOrSameTime, after.Add(skew))
+		behindEnd, _ := behindSpan.EndTime()
+		e.Expect(behindEnd).ToBeTemporally(matchers.AfterOrSameTime, before.Add(-skew))
+
+		explicit := time.Unix(1600000000, 0)
+		_, span := oteltest.NewTracerProvider(oteltest.WithClockOffset(skew)).Tracer(t.Name()).Start(context.Background(), "explicit", otel.WithTimestamp(explicit))
+		e.Expect(span.(*oteltest.Span).StartTime()).ToEqual(explicit)
+	})
+}
@@ -0,0 +1,215 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oteltest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+)
+
+// Snapshot is the state of a Span at a point in time. Unlike a Span,
+// it does not change afterwards, so it can be stored and compared,
+// for example against the expectations in a golden file.
+type Snapshot struct {
+	Name          string
+	SpanContext   otel.SpanContext
+	ParentSpanID  otel.SpanID
+	SpanKind      otel.SpanKind
+	StartTime     time.Time
+	EndTime       time.Time
+	Ended         bool
+	StatusCode    codes.Code
+	StatusMessage string
+	Attributes    map[label.Key]label.Value
+	// ResourceAttributes are the attributes of the resource that
+	// produced the Span.
+	ResourceAttributes map[label.Key]label.Value
+	Events             []Event
+	// Links are sorted like the result of the LinksSlice method of a
+	// Span.
+	Links []otel.Link
+}
+
+// Snapshot returns the current state of s.
+func (s *Span) Snapshot() Snapshot {
+	s.lock.RLock()
+	events := make([]Event, len(s.events))
+	for i, e := range s.events {
+		events[i] = e
+		if e.Attributes != nil {
+			events[i].Attributes = make(map[label.Key]label.Value, len(e.Attributes))
+			for k, v := range e.Attributes {
+				events[i].Attributes[k] = v
+			}
+		}
+	}
+	snapshot := Snapshot{
+		Name:          s.name,
+		SpanContext:   s.spanContext,
+		ParentSpanID:  s.parentSpanID,
+		SpanKind:      s.spanKind,
+		StartTime:     s.startTime,
+		EndTime:       s.endTime,
+		Ended:         s.ended,
+		StatusCode:    s.statusCode,
+		StatusMessage: s.statusMessage,
+		Events:        events,
+	}
+	s.lock.RUnlock()
+
+	// Both methods acquire the lock themselves.
+	snapshot.Attributes = s.Attributes()
+	snapshot.ResourceAttributes = s.ResourceAttributes()
+	snapshot.Links = s.LinksSlice()
+	return snapshot
+}
+
+// DiffSpans returns a human readable description of the differences
+// between the expected and the got Snapshot, one difference per line.
+// It compares the name, kind, status, attributes, resource attributes,
+// events and links.
+// The span contexts and timestamps are not compared, because they
+// usually differ between test runs. An empty string means no
+// difference.
+func DiffSpans(expected, got Snapshot) string {
+	var d differ
+	if expected.Name != got.Name {
+		d.addf("name: expected %q, got %q", expected.Name, got.Name)
+	}
+	if expected.SpanKind != got.SpanKind {
+		d.addf("kind: expected %s, got %s", expected.SpanKind, got.SpanKind)
+	}
+	if expected.StatusCode != got.StatusCode || expected.StatusMessage != got.StatusMessage {
+		d.addf("status: expected %s %q, got %s %q", expected.StatusCode, expected.StatusMessage, got.StatusCode, got.StatusMessage)
+	}
+	d.attributes("attribute", expected.Attributes, got.Attributes)
+	d.attributes("resource attribute", expected.ResourceAttributes, got.ResourceAttributes)
+	d.events(expected.Events, got.Events)
+	d.links(expected.Links, got.Links)
+	return strings.Join(d.lines, "\n")
+}
+
+type differ struct {
+	lines []string
+}
+
+func (d *differ) addf(format string, args ...interface{}) {
+	d.lines = append(d.lines, fmt.Sprintf(format, args...))
+}
+
+func (d *differ) attributes(prefix string, expected, got map[label.Key]label.Value) {
+	keys := make([]string, 0, len(expected)+len(got))
+	for k := range expected {
+		keys = append(keys, string(k))
+	}
+	for k := range got {
+		if _, ok := expected[k]; !ok {
+			keys = append(keys, string(k))
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		ev, eok := expected[label.Key(k)]
+		gv, gok := got[label.Key(k)]
+		switch {
+		case !gok:
+			d.addf("%s %q: removed, expected %s", prefix, k, formatValue(ev))
+		case !eok:
+			d.addf("%s %q: added %s", prefix, k, formatValue(gv))
+		case ev != gv:
+			d.addf("%s %q: expected %s, got %s", prefix, k, formatValue(ev), formatValue(gv))
+		}
+	}
+}
+
+func (d *differ) events(expected, got []Event) {
+	if len(expected) != len(got) {
+		d.addf("events: expected %d, got %d", len(expected), len(got))
+	}
+	for i := 0; i < len(expected) && i < len(got); i++ {
+		if expected[i].Name != got[i].Name {
+			d.addf("event %d name: expected %q, got %q", i, expected[i].Name, got[i].Name)
+		}
+		d.attributes(fmt.Sprintf("event %d attribute", i), expected[i].Attributes, got[i].Attributes)
+	}
+}
+
+func (d *differ) links(expected, got []otel.Link) {
+	matched := make([]bool, len(got))
+	for _, el := range expected {
+		name := linkName(el.SpanContext)
+		i := findLink(got, matched, el.SpanContext)
+		if i < 0 {
+			d.addf("link %s: removed", name)
+			continue
+		}
+		matched[i] = true
+		gl := got[i]
+		if !el.SpanContext.Equal(gl.SpanContext) {
+			d.addf("link %s trace flags: expected %02x, got %02x", name, el.SpanContext.TraceFlags, gl.SpanContext.TraceFlags)
+		}
+		d.attributes(fmt.Sprintf("link %s attribute", name), labelMap(el.Attributes), labelMap(gl.Attributes))
+	}
+	for i, gl := range got {
+		if !matched[i] {
+			d.addf("link %s: added", linkName(gl.SpanContext))
+		}
+	}
+}
+
+// findLink returns the index of the unmatched link in links to the
+// span with the IDs of sc, preferring one with equal trace flags, or -1
+// if there is none.
+func findLink(links []otel.Link, matched []bool, sc otel.SpanContext) int {
+	found := -1
+	for i, l := range links {
+		if matched[i] {
+			continue
+		}
+		if l.SpanContext.Equal(sc) {
+			return i
+		}
+		if found < 0 && sameSpan(l.SpanContext, sc) {
+			found = i
+		}
+	}
+	return found
+}
+
+func linkName(sc otel.SpanContext) string {
+	return sc.TraceID.String() + "-" + sc.SpanID.String()
+}
+
+func labelMap(kvs []label.KeyValue) map[label.Key]label.Value {
+	m := make(map[label.Key]label.Value, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func formatValue(v label.Value) string {
+	if v.Type() == label.STRING {
+		return fmt.Sprintf("%q", v.AsString())
+	}
+	return fmt.Sprintf("%s(%s)", v.Type(), v.Emit())
+}
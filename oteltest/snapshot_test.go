@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oteltest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/internal/matchers"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Run("#Snapshot", func(t *testing.T) {
+		t.Run("does not change with the span", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			tracer := oteltest.NewTracerProvider().Tracer(t.Name())
+			_, span := tracer.Start(context.Background(), "test", otel.WithAttributes(label.String("a", "1")))
+			subject := span.(*oteltest.Span)
+			snapshot := subject.Snapshot()
+
+			subject.SetName("renamed")
+			subject.SetAttributes(label.String("a", "2"))
+			subject.AddEvent("event")
+			subject.End()
+
+			e.Expect(snapshot.Name).ToEqual("test")
+			e.Expect(snapshot.Attributes).ToEqual(map[label.Key]label.Value{"a": label.StringValue("1")})
+			e.Expect(len(snapshot.Events)).ToEqual(0)
+			e.Expect(snapshot.Ended).ToBeFalse()
+			e.Expect(snapshot.SpanContext).ToEqual(subject.SpanContext())
+		})
+	})
+
+	t.Run("DiffSpans", func(t *testing.T) {
+		tracer := oteltest.NewTracerProvider().Tracer(t.Name())
+		_, linked := tracer.Start(context.Background(), "linked")
+		link := otel.Link{SpanContext: linked.SpanContext(), Attributes: []label.KeyValue{label.String("l", "1")}}
+
+		start := func() *oteltest.Span {
+			_, span := tracer.Start(context.Background(), "test",
+				otel.WithAttributes(label.String("a", "1"), label.Int("b", 2)),
+				otel.WithLinks(link),
+			)
+			return span.(*oteltest.Span)
+		}
+
+		t.Run("returns nothing for equal spans", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			e.Expect(oteltest.DiffSpans(start().Snapshot(), start().Snapshot())).ToEqual("")
+		})
+
+		t.Run("describes the differences", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			expected := start()
+			expected.AddEvent("event", otel.WithAttributes(label.String("e", "1")))
+
+			got := start()
+			got.SetName("other")
+			got.SetAttributes(label.String("a", "2"), label.Bool("c", true))
+			got.RecordError(errors.New("failed"))
+
+			want := `name: expected "test", got "other"
+status: expected Unset "", got Error ""
+attribute "a": expected "1", got "2"
+attribute "c": added BOOL(true)
+event 0 name: expected "event", got "error"
+event 0 attribute "e": removed, expected "1"
+event 0 attribute "error.message": added "failed"
+event 0 attribute "error.type": added "*errors.errorString"`
+			e.Expect(oteltest.DiffSpans(expected.Snapshot(), got.Snapshot())).ToEqual(want)
+		})
+
+		t.Run("describes link differences", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			expected := start().Snapshot()
+			got := start().Snapshot()
+			got.Links[0].Attributes = []label.KeyValue{label.String("l", "2")}
+			got.StatusCode = codes.Ok
+
+			name := linked.SpanContext().TraceID.String() + "-" + linked.SpanContext().SpanID.String()
+			want := `status: expected Unset "", got Ok ""
+link ` + name + ` attribute "l": expected "1", got "2"`
+			e.Expect(oteltest.DiffSpans(expected, got)).ToEqual(want)
+
+			got.Links = nil
+			e.Expect(oteltest.DiffSpans(expected, got)).ToEqual(`status: expected Unset "", got Ok ""
+link ` + name + `: removed`)
+
+			got = start().Snapshot()
+			got.Links[0].SpanContext.TraceFlags ^= otel.FlagsSampled
+			e.Expect(oteltest.DiffSpans(expected, got)).ToEqual(fmt.Sprintf("link %s trace flags: expected %02x, got %02x",
+				name, expected.Links[0].SpanContext.TraceFlags, got.Links[0].SpanContext.TraceFlags))
+		})
+	})
+}
@@ -15,10 +15,13 @@
 package oteltest
 
 import (
+	"bytes"
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
@@ -33,6 +36,25 @@ const (
 
 var _ otel.Span = (*Span)(nil)
 
+const (
+	// DemotedCurrent is the reason recorded for the span context of
+	// the current span demoted to a link by otel.WithNewRoot.
+	DemotedCurrent = "current"
+	// DemotedRemote is the reason recorded for the remote span context
+	// demoted to a link by otel.WithNewRoot.
+	DemotedRemote = "remote"
+)
+
+// DemotedParent is a span context that was turned into a link of a Span
+// instead of becoming its parent.
+type DemotedParent struct {
+	SpanContext otel.SpanContext
+	// Reason is DemotedCurrent or DemotedRemote. It matches the value of
+	// the "ignored-on-demand" attribute of the link, unless changed
+	// with WithDemotedParentLinkAttributes.
+	Reason string
+}
+
 // Span is an OpenTelemetry Span used for testing.
 type Span struct {
 	lock          sync.RWMutex
@@ -49,6 +71,10 @@ type Span struct {
 	events        []Event
 	links         map[otel.SpanContext][]label.KeyValue
 	spanKind      otel.SpanKind
+
+	demotedParents []DemotedParent
+
+	droppedAttributeBytes int
 }
 
 // Tracer returns the Tracer that created s.
@@ -61,19 +87,23 @@ func (s *Span) Tracer() otel.Tracer {
 // this method.
 func (s *Span) End(opts ...otel.SpanOption) {
 	s.lock.Lock()
-	defer s.lock.Unlock()
-
 	if s.ended {
+		s.lock.Unlock()
+		s.misuse("End")
 		return
 	}
 
 	c := otel.NewSpanConfig(opts...)
-	s.endTime = time.Now()
+	s.endTime = s.tracer.config.now()
 	if endTime := c.Timestamp; !endTime.IsZero() {
 		s.endTime = endTime
 	}
 
 	s.ended = true
+	s.lock.Unlock()
+
+	// The recorder is called without holding the lock, so it can read
+	// s with its accessors.
 	if s.tracer.config.SpanRecorder != nil {
 		s.tracer.config.SpanRecorder.OnEnd(s)
 	}
@@ -81,7 +111,11 @@ func (s *Span) End(opts ...otel.SpanOption) {
 
 // RecordError records an error as a Span event.
 func (s *Span) RecordError(err error, opts ...otel.EventOption) {
-	if err == nil || s.ended {
+	if err == nil {
+		return
+	}
+	if s.Ended() {
+		s.misuse("RecordError")
 		return
 	}
 
@@ -106,6 +140,7 @@ func (s *Span) AddEvent(name string, o ...otel.EventOption) {
 	defer s.lock.Unlock()
 
 	if s.ended {
+		s.misuse("AddEvent")
 		return
 	}
 
@@ -115,7 +150,7 @@ func (s *Span) AddEvent(name string, o ...otel.EventOption) {
 	if l := len(c.Attributes); l > 0 {
 		attributes = make(map[label.Key]label.Value, l)
 		for _, attr := range c.Attributes {
-			attributes[attr.Key] = attr.Value
+			attributes[attr.Key] = s.truncate(attr.Value)
 		}
 	}
 
@@ -126,6 +161,35 @@ func (s *Span) AddEvent(name string, o ...otel.EventOption) {
 	})
 }
 
+// AddLink adds a link to s after it was started, like a link passed
+// with otel.WithLinks at creation time. A link to the SpanContext of an
+// existing link replaces it. If the SpanRecorder of s implements
+// LinkRecorder, its OnAddLink method is called.
+func (s *Span) AddLink(link otel.Link) {
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		s.misuse("AddLink")
+		return
+	}
+
+	s.links[link.SpanContext] = append([]label.KeyValue{}, link.Attributes...)
+	s.lock.Unlock()
+
+	if lr, ok := s.tracer.config.SpanRecorder.(LinkRecorder); ok {
+		lr.OnAddLink(s, link)
+	}
+}
+
+// misuse panics if the TracerProvider that created s is in strict
+// mode, reporting that method was called on s after it ended.
+func (s *Span) misuse(method string) {
+	if s.tracer == nil || s.tracer.config == nil || !s.tracer.config.StrictMode {
+		return
+	}
+	panic(fmt.Sprintf("oteltest: %s called on span %q after it ended", method, s.name))
+}
+
 // IsRecording returns the recording state of s.
 func (s *Span) IsRecording() bool {
 	return true
@@ -142,6 +206,7 @@ func (s *Span) SetStatus(code codes.Code, msg string) {
 	defer s.lock.Unlock()
 
 	if s.ended {
+		s.misuse("SetStatus")
 		return
 	}
 
@@ -155,35 +220,123 @@ func (s *Span) SetName(name string) {
 	defer s.lock.Unlock()
 
 	if s.ended {
+		s.misuse("SetName")
 		return
 	}
 
 	s.name = name
 }
 
-// SetAttributes sets attrs as attributes of s.
+// SetAttributes sets attrs as attributes of s. If the SpanRecorder of s
+// implements AttributeRecorder, its OnSetAttributes method is called.
 func (s *Span) SetAttributes(attrs ...label.KeyValue) {
 	s.lock.Lock()
-	defer s.lock.Unlock()
-
 	if s.ended {
+		s.lock.Unlock()
+		s.misuse("SetAttributes")
 		return
 	}
 
-	for _, attr := range attrs {
-		s.attributes[attr.Key] = attr.Value
+	set := make([]label.KeyValue, len(attrs))
+	for i, attr := range attrs {
+		set[i] = label.KeyValue{Key: attr.Key, Value: s.truncate(attr.Value)}
+		s.attributes[attr.Key] = set[i].Value
 	}
+	s.lock.Unlock()
+
+	if ar, ok := s.tracer.config.SpanRecorder.(AttributeRecorder); ok && len(set) > 0 {
+		ar.OnSetAttributes(s, set)
+	}
+}
+
+// truncate applies the attribute value length limit of the
+// TracerProvider that created s to v. The caller must hold s.lock.
+func (s *Span) truncate(v label.Value) label.Value {
+	if s.tracer == nil || s.tracer.config == nil || s.tracer.config.AttributeValueLengthLimit <= 0 {
+		return v
+	}
+	limit := s.tracer.config.AttributeValueLengthLimit
+	switch v.Type() {
+	case label.STRING:
+		str, dropped := truncateString(v.AsString(), limit)
+		if dropped == 0 {
+			return v
+		}
+		s.droppedAttributeBytes += dropped
+		return label.StringValue(str)
+	case label.ARRAY:
+		arr := reflect.ValueOf(v.AsArray())
+		if arr.Type().Elem().Kind() != reflect.String {
+			return v
+		}
+		strs := make([]string, arr.Len())
+		total := 0
+		for i := range strs {
+			var dropped int
+			strs[i], dropped = truncateString(arr.Index(i).String(), limit)
+			total += dropped
+		}
+		if total == 0 {
+			return v
+		}
+		s.droppedAttributeBytes += total
+		return label.ArrayValue(strs)
+	}
+	return v
+}
+
+// truncateString cuts str to at most limit runes. It returns the result
+// and the number of bytes cut off.
+func truncateString(str string, limit int) (string, int) {
+	if utf8.RuneCountInString(str) <= limit {
+		return str, 0
+	}
+	n := 0
+	for i := range str {
+		if n == limit {
+			return str[:i], len(str) - i
+		}
+		n++
+	}
+	return str, 0
+}
+
+// DroppedAttributeBytes returns the number of bytes cut off the string
+// attribute values of s and its events because of the limit set with
+// WithAttributeValueLengthLimit.
+func (s *Span) DroppedAttributeBytes() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.droppedAttributeBytes
 }
 
 // Name returns the name most recently set on s, either at or after creation
 // time. It cannot be change after End has been called on s.
-func (s *Span) Name() string { return s.name }
+func (s *Span) Name() string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.name
+}
 
 // ParentSpanID returns the SpanID of the parent Span. If s is a root Span,
 // and therefore does not have a parent, the returned SpanID will be invalid
 // (i.e., it will contain all zeroes).
 func (s *Span) ParentSpanID() otel.SpanID { return s.parentSpanID }
 
+// IsChildOf returns whether s is a child of the span with the passed
+// SpanContext, i.e. whether s belongs to the same trace and its parent
+// SpanID is the SpanID of parent.
+func (s *Span) IsChildOf(parent otel.SpanContext) bool {
+	return sameSpan(otel.SpanContext{TraceID: s.spanContext.TraceID, SpanID: s.parentSpanID}, parent)
+}
+
+// sameSpan reports whether a and b are span contexts of the same span,
+// whatever their trace flags.
+func sameSpan(a, b otel.SpanContext) bool {
+	a.TraceFlags, b.TraceFlags = 0, 0
+	return a.Equal(b)
+}
+
 // Attributes returns the attributes set on s, either at or after creation
 // time. If the same attribute key was set multiple times, the last call will
 // be used. Attributes cannot be changed after End has been called on s.
@@ -200,13 +353,86 @@ func (s *Span) Attributes() map[label.Key]label.Value {
 	return attributes
 }
 
-// Events returns the events set on s. Events cannot be changed after End has
-// been called on s.
-func (s *Span) Events() []Event { return s.events }
+// ResourceAttributes returns the attributes of the resource that
+// produced s, set with WithResourceAttributes. They are distinct from
+// the attributes returned by Attributes. If the same key was passed
+// multiple times, the last value is used.
+func (s *Span) ResourceAttributes() map[label.Key]label.Value {
+	attributes := make(map[label.Key]label.Value)
+	if s.tracer == nil || s.tracer.config == nil {
+		return attributes
+	}
+	for _, kv := range s.tracer.config.ResourceAttributes {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
+
+// attribute returns the value of the attribute of s with the passed key.
+func (s *Span) attribute(key label.Key) (label.Value, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	v, ok := s.attributes[key]
+	return v, ok
+}
+
+// IntAttribute returns the value of the attribute of s with the passed
+// key if it is an INT32 or an INT64 value. The returned boolean is false
+// if s has no such attribute or the value has another type, which also
+// catches a number recorded as a string.
+func (s *Span) IntAttribute(key label.Key) (int64, bool) {
+	v, ok := s.attribute(key)
+	if !ok {
+		return 0, false
+	}
+	switch v.Type() {
+	case label.INT32:
+		return int64(v.AsInt32()), true
+	case label.INT64:
+		return v.AsInt64(), true
+	}
+	return 0, false
+}
+
+// StringAttribute returns the value of the attribute of s with the
+// passed key if it is a STRING value. The returned boolean is false if
+// s has no such attribute or the value has another type.
+func (s *Span) StringAttribute(key label.Key) (string, bool) {
+	v, ok := s.attribute(key)
+	if !ok || v.Type() != label.STRING {
+		return "", false
+	}
+	return v.AsString(), true
+}
+
+// BoolAttribute returns the value of the attribute of s with the passed
+// key if it is a BOOL value. The first returned boolean is the value and
+// the second one is false if s has no such attribute or the value has
+// another type.
+func (s *Span) BoolAttribute(key label.Key) (bool, bool) {
+	v, ok := s.attribute(key)
+	if !ok || v.Type() != label.BOOL {
+		return false, false
+	}
+	return v.AsBool(), true
+}
 
-// Links returns the links set on s at creation time. If multiple links for
-// the same SpanContext were set, the last link will be used.
+// Events returns a copy of the events set on s. Events cannot be changed
+// after End has been called on s.
+func (s *Span) Events() []Event {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]Event(nil), s.events...)
+}
+
+// Links returns the links set on s at creation time or with AddLink. If
+// multiple links for the same SpanContext were set, the last link will be
+// used.
 func (s *Span) Links() map[otel.SpanContext][]label.KeyValue {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
 	links := make(map[otel.SpanContext][]label.KeyValue)
 
 	for sc, attributes := range s.links {
@@ -216,6 +442,43 @@ func (s *Span) Links() map[otel.SpanContext][]label.KeyValue {
 	return links
 }
 
+// LinksSlice returns the links set on s at creation time or with
+// AddLink as a slice sorted by the trace ID and then the span ID of the
+// linked SpanContext. Unlike Links, the order is deterministic, which
+// makes the result suitable for serialization in golden tests.
+func (s *Span) LinksSlice() []otel.Link {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	links := make([]otel.Link, 0, len(s.links))
+	for sc, attributes := range s.links {
+		links = append(links, otel.Link{
+			SpanContext: sc,
+			Attributes:  append([]label.KeyValue{}, attributes...),
+		})
+	}
+	sort.Slice(links, func(i, j int) bool {
+		a, b := links[i].SpanContext, links[j].SpanContext
+		if c := bytes.Compare(a.TraceID[:], b.TraceID[:]); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(a.SpanID[:], b.SpanID[:]) < 0
+	})
+	return links
+}
+
+// DemotedParents returns the span contexts that would have been the
+// parent of s but were turned into links because s was started with
+// otel.WithNewRoot. The span context found as the current span comes
+// first, followed by the remote span context. The result is nil if
+// nothing was demoted.
+func (s *Span) DemotedParents() []DemotedParent {
+	if len(s.demotedParents) == 0 {
+		return nil
+	}
+	return append([]DemotedParent{}, s.demotedParents...)
+}
+
 // StartTime returns the time at which s was started. This will be the
 // wall-clock time unless a specific start time was provided.
 func (s *Span) StartTime() time.Time { return s.startTime }
@@ -223,20 +486,36 @@ func (s *Span) StartTime() time.Time { return s.startTime }
 // EndTime returns the time at which s was ended if at has been ended, or
 // false otherwise. If the span has been ended, the returned time will be the
 // wall-clock time unless a specific end time was provided.
-func (s *Span) EndTime() (time.Time, bool) { return s.endTime, s.ended }
+func (s *Span) EndTime() (time.Time, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.endTime, s.ended
+}
 
 // Ended returns whether s has been ended, i.e. whether End has been called at
 // least once on s.
-func (s *Span) Ended() bool { return s.ended }
+func (s *Span) Ended() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.ended
+}
 
 // StatusCode returns the code of the status most recently set on s, or
 // codes.OK if no status has been explicitly set. It cannot be changed after
 // End has been called on s.
-func (s *Span) StatusCode() codes.Code { return s.statusCode }
+func (s *Span) StatusCode() codes.Code {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.statusCode
+}
 
 // StatusMessage returns the status message most recently set on s or the
 // empty string if no status message was set.
-func (s *Span) StatusMessage() string { return s.statusMessage }
+func (s *Span) StatusMessage() string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.statusMessage
+}
 
 // SpanKind returns the span kind of s.
 func (s *Span) SpanKind() otel.SpanKind { return s.spanKind }
@@ -16,6 +16,7 @@ package oteltest_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sync"
@@ -273,6 +274,27 @@ func TestSpan(t *testing.T) {
 		})
 	})
 
+	t.Run("#IsChildOf", func(t *testing.T) {
+		tp := oteltest.NewTracerProvider()
+		t.Run("returns true only for the parent span", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			tracer := tp.Tracer(t.Name())
+			ctx, parent := tracer.Start(context.Background(), "parent")
+			_, span := tracer.Start(ctx, "child")
+			_, other := tracer.Start(context.Background(), "other")
+
+			subject, ok := span.(*oteltest.Span)
+			e.Expect(ok).ToBeTrue()
+
+			e.Expect(subject.IsChildOf(parent.SpanContext())).ToBeTrue()
+			e.Expect(subject.IsChildOf(subject.SpanContext())).ToBeFalse()
+			e.Expect(subject.IsChildOf(other.SpanContext())).ToBeFalse()
+		})
+	})
+
 	t.Run("#Name", func(t *testing.T) {
 		tp := oteltest.NewTracerProvider()
 		t.Run("returns the most recently set name on the span", func(t *testing.T) {
@@ -333,6 +355,30 @@ func TestSpan(t *testing.T) {
 			e.Expect(subject.Attributes()).ToEqual(map[label.Key]label.Value{})
 		})
 
+		t.Run("calls the AttributeRecorder for every change", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			sr := new(attributeRecorder)
+			tracer := oteltest.NewTracerProvider(
+				oteltest.WithSpanRecorder(sr),
+				oteltest.WithAttributeValueLengthLimit(3),
+			).Tracer(t.Name())
+			_, span := tracer.Start(context.Background(), "test", otel.WithAttributes(label.String("start", "s")))
+			span.SetAttributes(label.String("a", "1"), label.String("long", "truncated"))
+			span.SetAttributes()
+			span.SetAttributes(label.String("a", "1"))
+			span.End()
+			span.SetAttributes(label.String("ended", "e"))
+
+			e.Expect(sr.sets).ToEqual([][]label.KeyValue{
+				{label.String("start", "s")},
+				{label.String("a", "1"), label.String("long", "tru")},
+				{label.String("a", "1")},
+			})
+		})
+
 		t.Run("returns the most recently set attributes", func(t *testing.T) {
 			t.Parallel()
 
@@ -413,6 +459,63 @@ func TestSpan(t *testing.T) {
 		})
 	})
 
+	t.Run("#TypedAttributes", func(t *testing.T) {
+		tp := oteltest.NewTracerProvider()
+		t.Run("returns the values of the matching type", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			tracer := tp.Tracer(t.Name())
+			_, span := tracer.Start(context.Background(), "test", otel.WithAttributes(
+				label.Int("int", 42),
+				label.Int32("int32", 32),
+				label.String("string", "value"),
+				label.Bool("bool", true),
+			))
+
+			subject, ok := span.(*oteltest.Span)
+			e.Expect(ok).ToBeTrue()
+
+			i, ok := subject.IntAttribute("int")
+			e.Expect(ok).ToBeTrue()
+			e.Expect(i).ToEqual(int64(42))
+			i, ok = subject.IntAttribute("int32")
+			e.Expect(ok).ToBeTrue()
+			e.Expect(i).ToEqual(int64(32))
+			str, ok := subject.StringAttribute("string")
+			e.Expect(ok).ToBeTrue()
+			e.Expect(str).ToEqual("value")
+			b, ok := subject.BoolAttribute("bool")
+			e.Expect(ok).ToBeTrue()
+			e.Expect(b).ToBeTrue()
+		})
+
+		t.Run("returns false for missing attributes and other types", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			tracer := tp.Tracer(t.Name())
+			_, span := tracer.Start(context.Background(), "test", otel.WithAttributes(
+				label.String("status", "200"),
+				label.Int("count", 1),
+			))
+
+			subject, ok := span.(*oteltest.Span)
+			e.Expect(ok).ToBeTrue()
+
+			_, ok = subject.IntAttribute("status")
+			e.Expect(ok).ToBeFalse()
+			_, ok = subject.StringAttribute("count")
+			e.Expect(ok).ToBeFalse()
+			_, ok = subject.BoolAttribute("count")
+			e.Expect(ok).ToBeFalse()
+			_, ok = subject.IntAttribute("missing")
+			e.Expect(ok).ToBeFalse()
+		})
+	})
+
 	t.Run("#Links", func(t *testing.T) {
 		tp := oteltest.NewTracerProvider()
 		t.Run("returns an empty map by default", func(t *testing.T) {
@@ -430,6 +533,114 @@ func TestSpan(t *testing.T) {
 		})
 	})
 
+	t.Run("#LinksSlice", func(t *testing.T) {
+		tp := oteltest.NewTracerProvider()
+		t.Run("returns the links sorted by trace and span ID", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			scs := []otel.SpanContext{
+				{TraceID: otel.TraceID{2}, SpanID: otel.SpanID{1}},
+				{TraceID: otel.TraceID{1}, SpanID: otel.SpanID{2}},
+				{TraceID: otel.TraceID{1}, SpanID: otel.SpanID{1}},
+			}
+			var links []otel.Link
+			for i, sc := range scs {
+				links = append(links, otel.Link{
+					SpanContext: sc,
+					Attributes:  []label.KeyValue{label.Int("index", i)},
+				})
+			}
+
+			tracer := tp.Tracer(t.Name())
+			_, span := tracer.Start(context.Background(), "test", otel.WithLinks(links...))
+
+			subject, ok := span.(*oteltest.Span)
+			e.Expect(ok).ToBeTrue()
+
+			e.Expect(subject.LinksSlice()).ToEqual([]otel.Link{links[2], links[1], links[0]})
+		})
+
+		t.Run("marshals to identical JSON", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			var links []otel.Link
+			for i := 1; i <= 10; i++ {
+				links = append(links, otel.Link{
+					SpanContext: otel.SpanContext{TraceID: otel.TraceID{byte(i)}, SpanID: otel.SpanID{byte(i)}},
+					Attributes:  []label.KeyValue{label.Int("index", i)},
+				})
+			}
+
+			tracer := tp.Tracer(t.Name())
+			_, span := tracer.Start(context.Background(), "test", otel.WithLinks(links...))
+
+			subject, ok := span.(*oteltest.Span)
+			e.Expect(ok).ToBeTrue()
+
+			first, err := json.Marshal(subject.LinksSlice())
+			e.Expect(err).ToBeNil()
+			second, err := json.Marshal(subject.LinksSlice())
+			e.Expect(err).ToBeNil()
+
+			e.Expect(string(first)).ToEqual(string(second))
+		})
+	})
+
+	t.Run("#AddLink", func(t *testing.T) {
+		t.Run("adds a link after start", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			sr := new(linkRecorder)
+			tracer := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)).Tracer(t.Name())
+			startLink := otel.Link{
+				SpanContext: otel.SpanContext{TraceID: otel.TraceID{2}, SpanID: otel.SpanID{2}},
+				Attributes:  []label.KeyValue{label.String("added", "at start")},
+			}
+			_, span := tracer.Start(context.Background(), "test", otel.WithLinks(startLink))
+
+			subject, ok := span.(*oteltest.Span)
+			e.Expect(ok).ToBeTrue()
+
+			link := otel.Link{
+				SpanContext: otel.SpanContext{TraceID: otel.TraceID{1}, SpanID: otel.SpanID{1}},
+				Attributes:  []label.KeyValue{label.String("added", "after start")},
+			}
+			subject.AddLink(link)
+
+			e.Expect(subject.Links()).ToEqual(map[otel.SpanContext][]label.KeyValue{
+				startLink.SpanContext: startLink.Attributes,
+				link.SpanContext:      link.Attributes,
+			})
+			e.Expect(subject.LinksSlice()).ToEqual([]otel.Link{link, startLink})
+			e.Expect(sr.links).ToEqual([]otel.Link{link})
+		})
+
+		t.Run("does not add a link after end", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			sr := new(linkRecorder)
+			tracer := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)).Tracer(t.Name())
+			_, span := tracer.Start(context.Background(), "test")
+
+			subject, ok := span.(*oteltest.Span)
+			e.Expect(ok).ToBeTrue()
+
+			subject.End()
+			subject.AddLink(otel.Link{SpanContext: otel.SpanContext{TraceID: otel.TraceID{1}, SpanID: otel.SpanID{1}}})
+
+			e.Expect(len(subject.Links())).ToEqual(0)
+			e.Expect(len(sr.links)).ToEqual(0)
+		})
+	})
+
 	t.Run("#Events", func(t *testing.T) {
 		tp := oteltest.NewTracerProvider()
 		t.Run("returns an empty slice by default", func(t *testing.T) {
@@ -551,6 +762,7 @@ func TestSpan(t *testing.T) {
 		}
 
 		for _, status := range statuses {
+			status := status
 			t.Run("returns the most recently set status on the span", func(t *testing.T) {
 				t.Parallel()
 
@@ -610,4 +822,122 @@ func TestSpan(t *testing.T) {
 			e.Expect(subject.SpanKind()).ToEqual(otel.SpanKindConsumer)
 		})
 	})
+	t.Run("#StrictMode", func(t *testing.T) {
+		tp := oteltest.NewTracerProvider(oteltest.WithStrictMode())
+		misuses := map[string]func(otel.Span){
+			"End":           func(s otel.Span) { s.End() },
+			"SetAttributes": func(s otel.Span) { s.SetAttributes(label.String("key", "value")) },
+			"AddEvent":      func(s otel.Span) { s.AddEvent("event") },
+			"RecordError":   func(s otel.Span) { s.RecordError(errors.New("failed")) },
+			"SetStatus":     func(s otel.Span) { s.SetStatus(codes.Error, "failed") },
+			"SetName":       func(s otel.Span) { s.SetName("renamed") },
+			"AddLink": func(s otel.Span) {
+				s.(*oteltest.Span).AddLink(otel.Link{SpanContext: s.SpanContext()})
+			},
+		}
+		for method, misuse := range misuses {
+			method, misuse := method, misuse
+			t.Run(fmt.Sprintf("panics on %s after End", method), func(t *testing.T) {
+				t.Parallel()
+
+				e := matchers.NewExpecter(t)
+
+				_, subject := tp.Tracer(t.Name()).Start(context.Background(), "test")
+				subject.End()
+
+				var recovered interface{}
+				func() {
+					defer func() { recovered = recover() }()
+					misuse(subject)
+				}()
+				e.Expect(recovered).ToEqual(fmt.Sprintf("oteltest: %s called on span %q after it ended", method, "test"))
+			})
+		}
+
+		t.Run("does not panic without strict mode", func(t *testing.T) {
+			t.Parallel()
+
+			_, subject := oteltest.NewTracerProvider().Tracer(t.Name()).Start(context.Background(), "test")
+			subject.End()
+			for _, misuse := range misuses {
+				misuse(subject)
+			}
+		})
+	})
+
+	t.Run("#DroppedAttributeBytes", func(t *testing.T) {
+		tp := oteltest.NewTracerProvider(oteltest.WithAttributeValueLengthLimit(3))
+
+		t.Run("truncates string and string array attribute values", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			tracer := tp.Tracer(t.Name())
+			_, span := tracer.Start(context.Background(), "test",
+				otel.WithAttributes(label.String("start", "abcdef")))
+
+			subject, ok := span.(*oteltest.Span)
+			e.Expect(ok).ToBeTrue()
+
+			subject.SetAttributes(
+				label.String("short", "abc"),
+				label.String("multibyte", "äöüß"),
+				label.Array("array", []string{"abcd", "ab"}),
+				label.Int("int", 123456),
+			)
+			subject.AddEvent("event", otel.WithAttributes(label.String("event", "abcde")))
+			subject.End()
+
+			e.Expect(subject.Attributes()).ToEqual(map[label.Key]label.Value{
+				"start":     label.StringValue("abc"),
+				"short":     label.StringValue("abc"),
+				"multibyte": label.StringValue("äöü"),
+				"array":     label.ArrayValue([]string{"abc", "ab"}),
+				"int":       label.IntValue(123456),
+			})
+			e.Expect(subject.Events()[0].Attributes).ToEqual(map[label.Key]label.Value{
+				"event": label.StringValue("abc"),
+			})
+			// 3 bytes from "start", 2 from "multibyte", 1 from "array"
+			// and 2 from "event".
+			e.Expect(subject.DroppedAttributeBytes()).ToEqual(8)
+		})
+
+		t.Run("is zero without a limit", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			tracer := oteltest.NewTracerProvider().Tracer(t.Name())
+			_, span := tracer.Start(context.Background(), "test")
+
+			subject, ok := span.(*oteltest.Span)
+			e.Expect(ok).ToBeTrue()
+
+			subject.SetAttributes(label.String("long", "abcdef"))
+			e.Expect(subject.Attributes()["long"]).ToEqual(label.StringValue("abcdef"))
+			e.Expect(subject.DroppedAttributeBytes()).ToEqual(0)
+		})
+	})
+}
+
+type linkRecorder struct {
+	oteltest.StandardSpanRecorder
+
+	links []otel.Link
+}
+
+func (r *linkRecorder) OnAddLink(_ *oteltest.Span, link otel.Link) {
+	r.links = append(r.links, link)
+}
+
+type attributeRecorder struct {
+	oteltest.StandardSpanRecorder
+
+	sets [][]label.KeyValue
+}
+
+func (r *attributeRecorder) OnSetAttributes(_ *oteltest.Span, attrs []label.KeyValue) {
+	r.sets = append(r.sets, attrs)
 }
@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oteltest
+
+import (
+	"go.opentelemetry.io/otel"
+)
+
+// SpanNode is a Span in a SpanTree together with its children.
+type SpanNode struct {
+	Span     *Span
+	Children []*SpanNode
+}
+
+// ChildNames returns the names of the children of n in the order they
+// were passed to NewSpanTree.
+func (n *SpanNode) ChildNames() []string {
+	names := make([]string, 0, len(n.Children))
+	for _, c := range n.Children {
+		names = append(names, c.Span.Name())
+	}
+	return names
+}
+
+// SpanTree is the parent-child structure of a set of Spans, for
+// example the Completed spans of a StandardSpanRecorder.
+type SpanTree struct {
+	roots []*SpanNode
+	nodes []*SpanNode
+	byID  map[otel.SpanID]*SpanNode
+}
+
+// NewSpanTree builds the SpanTree of spans. The parent of a Span is the
+// Span whose span ID is the ParentSpanID of the Span. Spans without a
+// parent among spans are roots of the tree. Children and roots keep
+// the order of spans.
+func NewSpanTree(spans []*Span) *SpanTree {
+	t := &SpanTree{
+		nodes: make([]*SpanNode, 0, len(spans)),
+		byID:  make(map[otel.SpanID]*SpanNode, len(spans)),
+	}
+	for _, s := range spans {
+		n := &SpanNode{Span: s}
+		t.nodes = append(t.nodes, n)
+		t.byID[s.SpanContext().SpanID] = n
+	}
+	for _, n := range t.nodes {
+		parent, ok := t.byID[n.Span.ParentSpanID()]
+		if !ok || !n.Span.ParentSpanID().IsValid() || parent == n {
+			t.roots = append(t.roots, n)
+			continue
+		}
+		parent.Children = append(parent.Children, n)
+	}
+	return t
+}
+
+// Roots returns the nodes of the Spans without a parent in t.
+func (t *SpanTree) Roots() []*SpanNode {
+	return append([]*SpanNode{}, t.roots...)
+}
+
+// Node returns the node of the Span with the span ID id, or nil if t
+// has no such Span.
+func (t *SpanTree) Node(id otel.SpanID) *SpanNode {
+	return t.byID[id]
+}
+
+// Find returns the node of the first Span named name, or nil if t has
+// no such Span.
+func (t *SpanTree) Find(name string) *SpanNode {
+	for _, n := range t.nodes {
+		if n.Span.Name() == name {
+			return n
+		}
+	}
+	return nil
+}
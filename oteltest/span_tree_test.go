@@ -0,0 +1,80 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oteltest_test
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/internal/matchers"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestSpanTree(t *testing.T) {
+	t.Run("#NewSpanTree", func(t *testing.T) {
+		t.Run("builds the tree of the ended spans", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			sr := new(oteltest.StandardSpanRecorder)
+			tracer := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)).Tracer(t.Name())
+
+			ctx, root := tracer.Start(context.Background(), "root")
+			childCtx, child1 := tracer.Start(ctx, "child1")
+			_, grandchild := tracer.Start(childCtx, "grandchild")
+			_, child2 := tracer.Start(ctx, "child2")
+			_, other := tracer.Start(context.Background(), "other")
+			for _, s := range []otel.Span{grandchild, child1, child2, root, other} {
+				s.End()
+			}
+
+			tree := oteltest.NewSpanTree(sr.Completed())
+
+			roots := tree.Roots()
+			e.Expect(len(roots)).ToEqual(2)
+			e.Expect(roots[0].Span.Name()).ToEqual("root")
+			e.Expect(roots[1].Span.Name()).ToEqual("other")
+
+			e.Expect(tree.Find("root").ChildNames()).ToEqual([]string{"child1", "child2"})
+			e.Expect(tree.Find("child1").ChildNames()).ToEqual([]string{"grandchild"})
+			e.Expect(tree.Find("child2").ChildNames()).ToEqual([]string{})
+			e.Expect(tree.Find("missing") == nil).ToBeTrue()
+
+			e.Expect(tree.Node(child1.SpanContext().SpanID) == tree.Find("child1")).ToBeTrue()
+		})
+
+		t.Run("makes spans with a missing parent roots", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			sr := new(oteltest.StandardSpanRecorder)
+			tracer := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)).Tracer(t.Name())
+
+			ctx, parent := tracer.Start(context.Background(), "unfinished")
+			_, child := tracer.Start(ctx, "child")
+			child.End()
+
+			tree := oteltest.NewSpanTree(sr.Completed())
+
+			roots := tree.Roots()
+			e.Expect(len(roots)).ToEqual(1)
+			e.Expect(roots[0].Span.Name()).ToEqual("child")
+			e.Expect(tree.Node(parent.SpanContext().SpanID) == nil).ToBeTrue()
+		})
+	})
+}
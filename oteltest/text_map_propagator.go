@@ -17,6 +17,7 @@ package oteltest
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -127,6 +128,99 @@ func (c *TextMapCarrier) Reset() {
 	c.data = make(map[string]string)
 }
 
+// CarrierOperationKind describes the kind of a call made to a
+// RecordingCarrier.
+type CarrierOperationKind int
+
+const (
+	// CarrierGet is a call to the Get method.
+	CarrierGet CarrierOperationKind = iota
+	// CarrierSet is a call to the Set method.
+	CarrierSet
+)
+
+// CarrierOperation is a single call made to a RecordingCarrier.
+type CarrierOperation struct {
+	Kind CarrierOperationKind
+	Key  string
+	// Value is the value returned by Get or passed to Set.
+	Value string
+}
+
+// RecordingCarrier is a TextMapCarrier that records the order of all
+// the calls made to it. It can be used to test the order in which
+// composed propagators read and write their fields.
+type RecordingCarrier struct {
+	mtx sync.Mutex
+
+	ops  []CarrierOperation
+	keys []string
+	data map[string]string
+}
+
+var _ otel.TextMapCarrier = (*RecordingCarrier)(nil)
+
+// NewRecordingCarrier returns a new *RecordingCarrier populated with data.
+func NewRecordingCarrier(data map[string]string) *RecordingCarrier {
+	c := &RecordingCarrier{data: make(map[string]string, len(data))}
+	for k, v := range data {
+		c.keys = append(c.keys, k)
+		c.data[k] = v
+	}
+	sort.Strings(c.keys)
+	return c
+}
+
+// Get returns the value associated with the passed key.
+func (c *RecordingCarrier) Get(key string) string {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	value := c.data[key]
+	c.ops = append(c.ops, CarrierOperation{Kind: CarrierGet, Key: key, Value: value})
+	return value
+}
+
+// Set stores the key-value pair.
+func (c *RecordingCarrier) Set(key, value string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.ops = append(c.ops, CarrierOperation{Kind: CarrierSet, Key: key, Value: value})
+	if _, ok := c.data[key]; !ok {
+		c.keys = append(c.keys, key)
+	}
+	c.data[key] = value
+}
+
+// Keys returns the keys stored in c. The keys of the initial data
+// come first in sorted order, followed by the keys added with Set in
+// the order they were first set.
+func (c *RecordingCarrier) Keys() []string {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return append([]string{}, c.keys...)
+}
+
+// Operations returns all the calls made to c in the order they were
+// made.
+func (c *RecordingCarrier) Operations() []CarrierOperation {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return append([]CarrierOperation{}, c.ops...)
+}
+
+// SetOrder returns the keys passed to Set in the order of the calls.
+func (c *RecordingCarrier) SetOrder() []string {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	var keys []string
+	for _, op := range c.ops {
+		if op.Kind == CarrierSet {
+			keys = append(keys, op.Key)
+		}
+	}
+	return keys
+}
+
 type state struct {
 	Injections  uint64
 	Extractions uint64
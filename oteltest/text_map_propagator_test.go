@@ -16,6 +16,7 @@ package oteltest
 
 import (
 	"context"
+	"reflect"
 	"testing"
 )
 
@@ -122,3 +123,33 @@ func TestNewStateEmpty(t *testing.T) {
 		t.Errorf("newState(\"\") returned %v, want %v", got, want)
 	}
 }
+
+func TestRecordingCarrier(t *testing.T) {
+	rc := NewRecordingCarrier(map[string]string{"b": "2", "a": "1"})
+	if got := rc.Get("a"); got != "1" {
+		t.Errorf("RecordingCarrier.Get(%q) returned %q, want %q", "a", got, "1")
+	}
+	rc.Set("traceparent", "tp")
+	rc.Set("baggage", "bg")
+	rc.Set("traceparent", "tp2")
+	rc.Get("missing")
+
+	wantOps := []CarrierOperation{
+		{Kind: CarrierGet, Key: "a", Value: "1"},
+		{Kind: CarrierSet, Key: "traceparent", Value: "tp"},
+		{Kind: CarrierSet, Key: "baggage", Value: "bg"},
+		{Kind: CarrierSet, Key: "traceparent", Value: "tp2"},
+		{Kind: CarrierGet, Key: "missing"},
+	}
+	if got := rc.Operations(); !reflect.DeepEqual(got, wantOps) {
+		t.Errorf("RecordingCarrier.Operations() returned %v, want %v", got, wantOps)
+	}
+	wantSets := []string{"traceparent", "baggage", "traceparent"}
+	if got := rc.SetOrder(); !reflect.DeepEqual(got, wantSets) {
+		t.Errorf("RecordingCarrier.SetOrder() returned %v, want %v", got, wantSets)
+	}
+	wantKeys := []string{"a", "b", "traceparent", "baggage"}
+	if got := rc.Keys(); !reflect.DeepEqual(got, wantKeys) {
+		t.Errorf("RecordingCarrier.Keys() returned %v, want %v", got, wantKeys)
+	}
+}
@@ -16,9 +16,10 @@ package oteltest
 
 import (
 	"context"
-	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/internal/trace/noop"
+	otelparent "go.opentelemetry.io/otel/internal/trace/parent"
 	"go.opentelemetry.io/otel/label"
 )
 
@@ -31,14 +32,20 @@ type Tracer struct {
 	// Version is the instrumentation version.
 	Version string
 
-	config *config
+	config   *config
+	provider *TracerProvider
 }
 
 // Start creates a span. If t is configured with a SpanRecorder its OnStart
-// method will be called after the created Span has been initialized.
+// method will be called after the created Span has been initialized. If
+// the TracerProvider that created t has been shut down, a non-recording
+// span is returned instead.
 func (t *Tracer) Start(ctx context.Context, name string, opts ...otel.SpanOption) (context.Context, otel.Span) {
+	if t.provider != nil && t.provider.shutdown() {
+		return noop.Tracer.Start(ctx, name)
+	}
 	c := otel.NewSpanConfig(opts...)
-	startTime := time.Now()
+	startTime := t.config.now()
 	if st := c.Timestamp; !st.IsZero() {
 		startTime = st
 	}
@@ -54,21 +61,35 @@ func (t *Tracer) Start(ctx context.Context, name string, opts ...otel.SpanOption
 	if c.NewRoot {
 		span.spanContext = otel.SpanContext{}
 
-		iodKey := label.Key("ignored-on-demand")
+		linkAttributes := t.config.DemotedParentLinkAttributes
+		if linkAttributes == nil {
+			linkAttributes = otelparent.DefaultLinkAttributes
+		}
 		if lsc := otel.SpanFromContext(ctx).SpanContext(); lsc.IsValid() {
-			span.links[lsc] = []label.KeyValue{iodKey.String("current")}
+			span.links[lsc] = linkAttributes(DemotedCurrent)
+			span.demotedParents = append(span.demotedParents, DemotedParent{SpanContext: lsc, Reason: DemotedCurrent})
 		}
 		if rsc := otel.RemoteSpanContextFromContext(ctx); rsc.IsValid() {
-			span.links[rsc] = []label.KeyValue{iodKey.String("remote")}
+			span.links[rsc] = linkAttributes(DemotedRemote)
+			span.demotedParents = append(span.demotedParents, DemotedParent{SpanContext: rsc, Reason: DemotedRemote})
 		}
 	} else {
-		span.spanContext = t.config.SpanContextFunc(ctx)
-		if lsc := otel.SpanFromContext(ctx).SpanContext(); lsc.IsValid() {
-			span.spanContext.TraceID = lsc.TraceID
-			span.parentSpanID = lsc.SpanID
-		} else if rsc := otel.RemoteSpanContextFromContext(ctx); rsc.IsValid() {
-			span.spanContext.TraceID = rsc.TraceID
-			span.parentSpanID = rsc.SpanID
+		if t.config.NameSpanContextFunc != nil {
+			span.spanContext = t.config.NameSpanContextFunc(ctx, name)
+		} else {
+			span.spanContext = t.config.SpanContextFunc(ctx)
+		}
+		parent := otel.SpanFromContext(ctx).SpanContext()
+		if !parent.IsValid() {
+			parent = otel.RemoteSpanContextFromContext(ctx)
+		}
+		if parent.IsValid() {
+			span.spanContext.TraceID = parent.TraceID
+			// Children inherit the sampling decision of their parent,
+			// whatever the SpanContextFunc returned.
+			span.spanContext.TraceFlags &^= otel.FlagsSampled
+			span.spanContext.TraceFlags |= parent.TraceFlags & otel.FlagsSampled
+			span.parentSpanID = parent.SpanID
 		}
 	}
 
@@ -77,6 +98,7 @@ func (t *Tracer) Start(ctx context.Context, name string, opts ...otel.SpanOption
 	}
 
 	span.SetName(name)
+	span.SetAttributes(t.config.KindDefaultAttributes[c.SpanKind]...)
 	span.SetAttributes(c.Attributes...)
 
 	if t.config.SpanRecorder != nil {
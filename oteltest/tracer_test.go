@@ -46,6 +46,29 @@ func TestTracer(t *testing.T) {
 			return span, nil
 		})
 
+		t.Run("returns a non-recording span after the provider is shut down", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			sr := new(oteltest.StandardSpanRecorder)
+			tp := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+			subject := tp.Tracer(t.Name())
+
+			_, before := subject.Start(context.Background(), "before")
+			e.Expect(before.IsRecording()).ToBeTrue()
+
+			tp.Shutdown()
+
+			_, after := subject.Start(context.Background(), "after")
+			e.Expect(after.IsRecording()).ToBeFalse()
+			e.Expect(after.SpanContext().IsValid()).ToBeFalse()
+
+			_, ok := after.(*oteltest.Span)
+			e.Expect(ok).ToBeFalse()
+			e.Expect(sr.Started()).ToEqual([]*oteltest.Span{before.(*oteltest.Span)})
+		})
+
 		t.Run("uses the start time from WithTimestamp", func(t *testing.T) {
 			t.Parallel()
 
@@ -97,9 +120,8 @@ func TestTracer(t *testing.T) {
 			e.Expect(ok).ToBeTrue()
 
 			childSpanContext := testSpan.SpanContext()
-			e.Expect(childSpanContext.TraceID).ToEqual(parentSpanContext.TraceID)
+			e.Expect(testSpan.IsChildOf(parentSpanContext)).ToBeTrue()
 			e.Expect(childSpanContext.SpanID).NotToEqual(parentSpanContext.SpanID)
-			e.Expect(testSpan.ParentSpanID()).ToEqual(parentSpanContext.SpanID)
 		})
 
 		t.Run("uses the current span from context as parent, even if it has remote span context", func(t *testing.T) {
@@ -120,9 +142,8 @@ func TestTracer(t *testing.T) {
 			e.Expect(ok).ToBeTrue()
 
 			childSpanContext := testSpan.SpanContext()
-			e.Expect(childSpanContext.TraceID).ToEqual(parentSpanContext.TraceID)
+			e.Expect(testSpan.IsChildOf(parentSpanContext)).ToBeTrue()
 			e.Expect(childSpanContext.SpanID).NotToEqual(parentSpanContext.SpanID)
-			e.Expect(testSpan.ParentSpanID()).ToEqual(parentSpanContext.SpanID)
 		})
 
 		t.Run("uses the remote span context from context as parent, if current span is missing", func(t *testing.T) {
@@ -142,9 +163,36 @@ func TestTracer(t *testing.T) {
 			e.Expect(ok).ToBeTrue()
 
 			childSpanContext := testSpan.SpanContext()
-			e.Expect(childSpanContext.TraceID).ToEqual(remoteParentSpanContext.TraceID)
+			e.Expect(testSpan.IsChildOf(remoteParentSpanContext)).ToBeTrue()
 			e.Expect(childSpanContext.SpanID).NotToEqual(remoteParentSpanContext.SpanID)
-			e.Expect(testSpan.ParentSpanID()).ToEqual(remoteParentSpanContext.SpanID)
+		})
+
+		t.Run("inherits the sampled flag of the parent", func(t *testing.T) {
+			t.Parallel()
+
+			var spanID uint64
+			// The SpanContextFunc sets every flag, so only the sampled
+			// flag of the parent is inherited.
+			tp := oteltest.NewTracerProvider(oteltest.WithSpanContextFunc(func(context.Context) otel.SpanContext {
+				sc := otel.SpanContext{TraceID: otel.TraceID{1}, TraceFlags: 0xff}
+				sc.SpanID[7] = byte(atomic.AddUint64(&spanID, 1))
+				return sc
+			}))
+			subject := tp.Tracer(t.Name())
+
+			for _, sampled := range []bool{true, false} {
+				e := matchers.NewExpecter(t)
+
+				ctx, err := oteltest.ContextWithRemoteParent(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", sampled)
+				e.Expect(err).ToBeNil()
+
+				ctx, parent := subject.Start(ctx, "parent")
+				_, child := subject.Start(ctx, "child")
+
+				e.Expect(parent.SpanContext().IsSampled()).ToEqual(sampled)
+				e.Expect(child.SpanContext().IsSampled()).ToEqual(sampled)
+				e.Expect(child.SpanContext().IsDebug()).ToBeTrue()
+			}
 		})
 
 		t.Run("creates new root when both current span and remote span context are missing", func(t *testing.T) {
@@ -220,6 +268,33 @@ func TestTracer(t *testing.T) {
 				})
 			}
 			e.Expect(gotLinks).ToMatchInAnyOrder(expectedLinks)
+
+			e.Expect(testSpan.DemotedParents()).ToEqual([]oteltest.DemotedParent{
+				{SpanContext: parentSpanContext, Reason: oteltest.DemotedCurrent},
+				{SpanContext: remoteParentSpanContext, Reason: oteltest.DemotedRemote},
+			})
+		})
+
+		t.Run("uses the link attributes of WithDemotedParentLinkAttributes", func(t *testing.T) {
+			t.Parallel()
+
+			e := matchers.NewExpecter(t)
+
+			subject := oteltest.NewTracerProvider(
+				oteltest.WithDemotedParentLinkAttributes(func(reason string) []label.KeyValue {
+					return []label.KeyValue{label.String("link.reason", "demoted-"+reason)}
+				}),
+			).Tracer(t.Name())
+
+			parentCtx, parentSpan := subject.Start(context.Background(), "not-a-parent")
+			_, span := subject.Start(parentCtx, "child", otel.WithNewRoot())
+
+			testSpan, ok := span.(*oteltest.Span)
+			e.Expect(ok).ToBeTrue()
+			e.Expect(len(testSpan.Links())).ToEqual(1)
+			attrs, ok := linkAttributes(testSpan.Links(), parentSpan.SpanContext())
+			e.Expect(ok).ToBeTrue()
+			e.Expect(attrs).ToEqual([]label.KeyValue{label.String("link.reason", "demoted-current")})
 		})
 
 		t.Run("uses the links provided through WithLinks", func(t *testing.T) {
@@ -251,12 +326,27 @@ func TestTracer(t *testing.T) {
 			e.Expect(ok).ToBeTrue()
 
 			links := testSpan.Links()
-			e.Expect(links[link1.SpanContext]).ToEqual(link1.Attributes)
-			e.Expect(links[link2.SpanContext]).ToEqual(link2.Attributes)
+			e.Expect(len(links)).ToEqual(2)
+			for _, link := range []otel.Link{link1, link2} {
+				attrs, ok := linkAttributes(links, link.SpanContext)
+				e.Expect(ok).ToBeTrue()
+				e.Expect(attrs).ToEqual(link.Attributes)
+			}
 		})
 	})
 }
 
+// linkAttributes returns the attributes of the link of links whose span
+// context is equal to sc.
+func linkAttributes(links map[otel.SpanContext][]label.KeyValue, sc otel.SpanContext) ([]label.KeyValue, bool) {
+	for linkSC, attrs := range links {
+		if linkSC.Equal(sc) {
+			return attrs, true
+		}
+	}
+	return nil, false
+}
+
 func testTracedSpan(t *testing.T, fn func(tracer otel.Tracer, name string) (otel.Span, error)) {
 	tp := oteltest.NewTracerProvider()
 	t.Run("starts a span with the expected name", func(t *testing.T) {
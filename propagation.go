@@ -14,7 +14,12 @@
 
 package otel
 
-import "context"
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/internal/propagation"
+)
 
 // TextMapCarrier is the storage medium used by a TextMapPropagator.
 type TextMapCarrier interface {
@@ -45,7 +50,14 @@ func (p compositeTextMapPropagator) Inject(ctx context.Context, carrier TextMapC
 
 func (p compositeTextMapPropagator) Extract(ctx context.Context, carrier TextMapCarrier) context.Context {
 	for _, i := range p {
+		rsc, source := RemoteSpanContextFromContext(ctx), propagation.ExtractionSource(ctx)
 		ctx = i.Extract(ctx, carrier)
+		// Record the propagators that extract a span context without
+		// recording themselves, so the source of a span context of an
+		// earlier propagator is never kept for it.
+		if extracted := RemoteSpanContextFromContext(ctx); extracted.IsValid() && extracted != rsc && propagation.ExtractionSource(ctx) == source {
+			ctx = propagation.ContextWithExtractionSource(ctx, fmt.Sprintf("%T", i))
+		}
 	}
 	return ctx
 }
@@ -73,6 +85,17 @@ func (p compositeTextMapPropagator) Fields() []string {
 // concerns in the order the TextMapPropagators were provided. Additionally,
 // the Fields method will return a de-duplicated slice of the keys that are
 // set with the Inject method.
+//
+// Because every TextMapPropagator extracts into the Context returned by
+// the previous one, a later TextMapPropagator overrides what an earlier
+// one extracted for the same concern. To prefer one format when several
+// are present, for example W3C trace context over B3, pass its
+// TextMapPropagator last.
+//
+// The Extract method also records the TextMapPropagator whose remote
+// span context is kept, for the ExtractionSource function of the
+// propagators package. A TextMapPropagator not recording itself is
+// recorded with the name of its type.
 func NewCompositeTextMapPropagator(p ...TextMapPropagator) TextMapPropagator {
 	return compositeTextMapPropagator(p)
 }
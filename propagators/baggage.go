@@ -17,6 +17,7 @@ package propagators
 import (
 	"context"
 	"net/url"
+	"sort"
 	"strings"
 
 	"go.opentelemetry.io/otel"
@@ -28,86 +29,283 @@ import (
 // https://github.com/open-telemetry/opentelemetry-specification/blob/18b2752ebe6c7f0cdd8c7b2bcbdceb0ae3f5ad95/specification/correlationcontext/api.md#header-name
 const baggageHeader = "otcorrelations"
 
+// Limits of the baggage header defined by the W3C Baggage
+// specification.
+const (
+	maxBaggageMembers = 180
+	maxBaggageBytes   = 8192
+)
+
 // Baggage is a propagator that supports the W3C Baggage format.
 //
 // This propagates user-defined baggage associated with a trace. The complete
 // specification is defined at https://w3c.github.io/baggage/.
-type Baggage struct{}
+//
+// The zero value is ready to use. Use NewBaggage to create a Baggage
+// propagator with non-default behavior.
+type Baggage struct {
+	config baggageConfig
+}
 
 var _ otel.TextMapPropagator = Baggage{}
 
+type baggageConfig struct {
+	// mergeOnInject keeps the members already present in the
+	// carrier when injecting.
+	mergeOnInject bool
+	// strictEncoding makes Extract reject the headers with malformed
+	// members.
+	strictEncoding bool
+}
+
+// BaggageOption applies an option to a Baggage propagator.
+type BaggageOption interface {
+	Apply(*baggageConfig)
+}
+
+// NewBaggage returns a Baggage propagator configured with options.
+func NewBaggage(opts ...BaggageOption) Baggage {
+	var b Baggage
+	for _, opt := range opts {
+		opt.Apply(&b.config)
+	}
+	return b
+}
+
+type mergeOnInjectOption bool
+
+func (o mergeOnInjectOption) Apply(c *baggageConfig) {
+	c.mergeOnInject = bool(o)
+}
+
+// WithMergeOnInject makes Inject merge the baggage from the context
+// with the baggage members already present in the carrier instead of
+// replacing them. Members of the carrier with a key that is also
+// present in the context are replaced, all other members are kept.
+// This allows several independent writers to share the baggage
+// header.
+func WithMergeOnInject() BaggageOption {
+	return mergeOnInjectOption(true)
+}
+
+type strictEncodingOption bool
+
+func (o strictEncodingOption) Apply(c *baggageConfig) {
+	c.strictEncoding = bool(o)
+}
+
+// WithStrictEncoding makes Extract follow the baggage header format
+// strictly, which suits spec-conformance testing. A header with a
+// malformed member, like a member without a value, with whitespace
+// around its key or value, with an empty key or with an invalid
+// percent-encoding, is rejected as a whole. By default Extract is
+// lenient for interoperability: it trims the whitespace around the
+// keys and values and skips the malformed members.
+func WithStrictEncoding() BaggageOption {
+	return strictEncodingOption(true)
+}
+
 // Inject sets baggage key-values from ctx into the carrier.
+//
+// The injected header is kept within the limits of the W3C Baggage
+// specification of 180 members and 8192 bytes. If the baggage does
+// not fit, the largest members are dropped first, so that as many
+// members as possible are propagated.
+//
+// By default the baggage header is set with the carrier's Set method,
+// so any baggage already present in the carrier is replaced, see
+// WithMergeOnInject for a way to keep it.
+//
+// Values are escaped as a whole. Properties are only injected for the
+// baggage members set with ContextWithBaggageMembers.
 func (b Baggage) Inject(ctx context.Context, carrier otel.TextMapCarrier) {
 	baggageMap := baggage.MapFromContext(ctx)
-	firstIter := true
-	var headerValueBuilder strings.Builder
+	baggageMembers := BaggageMembersFromContext(ctx)
+	var members []string
+	var keys map[string]struct{}
+	if b.config.mergeOnInject {
+		keys = make(map[string]struct{}, baggageMap.Len())
+	}
 	baggageMap.Foreach(func(kv label.KeyValue) bool {
-		if !firstIter {
-			headerValueBuilder.WriteRune(',')
+		key := strings.TrimSpace((string)(kv.Key))
+		if keys != nil {
+			keys[key] = struct{}{}
 		}
-		firstIter = false
-		headerValueBuilder.WriteString(url.QueryEscape(strings.TrimSpace((string)(kv.Key))))
-		headerValueBuilder.WriteRune('=')
-		headerValueBuilder.WriteString(url.QueryEscape(strings.TrimSpace(kv.Value.Emit())))
+		value := kv.Value.Emit()
+		member := url.QueryEscape(key) + "=" + url.QueryEscape(strings.TrimSpace(value))
+		if m, ok := baggageMembers[key]; ok && m.Value == value {
+			member += encodeBaggageProperties(m.Properties)
+		}
+		members = append(members, member)
 		return true
 	})
-	if headerValueBuilder.Len() > 0 {
-		headerString := headerValueBuilder.String()
-		carrier.Set(baggageHeader, headerString)
+	if b.config.mergeOnInject && len(members) > 0 {
+		members = append(existingBaggageMembers(carrier, keys), members...)
+	}
+	members = truncateBaggageMembers(members)
+	if len(members) > 0 {
+		carrier.Set(baggageHeader, strings.Join(members, ","))
+	}
+}
+
+// truncateBaggageMembers drops the largest members until the rest
+// fits in the limits of the baggage header. The order of the kept
+// members is preserved.
+func truncateBaggageMembers(members []string) []string {
+	size := len(members) - 1 // separators
+	for _, m := range members {
+		size += len(m)
+	}
+	if len(members) <= maxBaggageMembers && size <= maxBaggageBytes {
+		return members
+	}
+
+	bySize := make([]int, len(members))
+	for i := range bySize {
+		bySize[i] = i
+	}
+	sort.SliceStable(bySize, func(i, j int) bool {
+		return len(members[bySize[i]]) > len(members[bySize[j]])
+	})
+	dropped := make(map[int]struct{})
+	count := len(members)
+	for _, idx := range bySize {
+		if count <= maxBaggageMembers && size <= maxBaggageBytes {
+			break
+		}
+		dropped[idx] = struct{}{}
+		count--
+		size -= len(members[idx]) + 1
 	}
+
+	kept := make([]string, 0, count)
+	for i, m := range members {
+		if _, ok := dropped[i]; !ok {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// existingBaggageMembers returns the baggage members present in the
+// carrier with keys other than the passed ones.
+func existingBaggageMembers(carrier otel.TextMapCarrier, keys map[string]struct{}) []string {
+	bVal := carrier.Get(baggageHeader)
+	if bVal == "" {
+		return nil
+	}
+	var members []string
+	for _, member := range strings.Split(bVal, ",") {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+		name := member
+		if idx := strings.IndexAny(member, "=;"); idx >= 0 {
+			name = member[:idx]
+		}
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if _, ok := keys[strings.TrimSpace(name)]; ok {
+			continue
+		}
+		members = append(members, member)
+	}
+	return members
 }
 
 // Extract returns a copy of parent with the baggage from the carrier added.
+// Malformed members are skipped, unless WithStrictEncoding is used. The
+// members with properties are also available with
+// BaggageMembersFromContext. BaggageSource is recorded as the extraction
+// source unless ctx already has one.
 func (b Baggage) Extract(parent context.Context, carrier otel.TextMapCarrier) context.Context {
-	bVal := carrier.Get(baggageHeader)
+	bVal := getField(carrier, baggageHeader)
 	if bVal == "" {
 		return parent
 	}
 
 	baggageValues := strings.Split(bVal, ",")
 	keyValues := make([]label.KeyValue, 0, len(baggageValues))
+	var members map[string]BaggageMember
 	for _, baggageValue := range baggageValues {
-		valueAndProps := strings.Split(baggageValue, ";")
-		if len(valueAndProps) < 1 {
-			continue
-		}
-		nameValue := strings.Split(valueAndProps[0], "=")
-		if len(nameValue) < 2 {
+		kv, member, ok := b.parseMember(baggageValue)
+		if !ok {
+			if b.config.strictEncoding {
+				return parent
+			}
 			continue
 		}
-		name, err := url.QueryUnescape(nameValue[0])
-		if err != nil {
-			continue
-		}
-		trimmedName := strings.TrimSpace(name)
-		value, err := url.QueryUnescape(nameValue[1])
-		if err != nil {
-			continue
+		keyValues = append(keyValues, kv)
+		if len(member.Properties) > 0 {
+			if members == nil {
+				members = make(map[string]BaggageMember)
+			}
+			members[string(kv.Key)] = member
 		}
-		trimmedValue := strings.TrimSpace(value)
-
-		// TODO (skaris): properties defiend https://w3c.github.io/correlation-context/, are currently
-		// just put as part of the value.
-		var trimmedValueWithProps strings.Builder
-		trimmedValueWithProps.WriteString(trimmedValue)
-		for _, prop := range valueAndProps[1:] {
-			trimmedValueWithProps.WriteRune(';')
-			trimmedValueWithProps.WriteString(prop)
-		}
-
-		keyValues = append(keyValues, label.String(trimmedName, trimmedValueWithProps.String()))
 	}
 
 	if len(keyValues) > 0 {
 		// Only update the context if valid values were found
-		return baggage.ContextWithMap(parent, baggage.NewMap(baggage.MapUpdate{
+		ctx := baggage.ContextWithMap(parent, baggage.NewMap(baggage.MapUpdate{
 			MultiKV: keyValues,
 		}))
+		if ExtractionSource(ctx) == "" {
+			ctx = ContextWithExtractionSource(ctx, BaggageSource)
+		}
+		return ContextWithBaggageMembers(ctx, members)
 	}
 
 	return parent
 }
 
+// parseMember parses a member of the baggage header, returning it both
+// as a baggage item and with its properties. It reports false for a
+// malformed member.
+func (b Baggage) parseMember(member string) (label.KeyValue, BaggageMember, bool) {
+	valueAndProps := strings.Split(member, ";")
+	nameValue := strings.Split(valueAndProps[0], "=")
+	if b.config.strictEncoding {
+		if len(nameValue) != 2 || nameValue[0] == "" || hasSurroundingSpace(nameValue[0]) || hasSurroundingSpace(nameValue[1]) {
+			return label.KeyValue{}, BaggageMember{}, false
+		}
+	} else if len(nameValue) < 2 {
+		return label.KeyValue{}, BaggageMember{}, false
+	}
+	name, err := url.QueryUnescape(nameValue[0])
+	if err != nil {
+		return label.KeyValue{}, BaggageMember{}, false
+	}
+	value, err := url.QueryUnescape(nameValue[1])
+	if err != nil {
+		return label.KeyValue{}, BaggageMember{}, false
+	}
+	trimmedName, trimmedValue := name, value
+	if !b.config.strictEncoding {
+		trimmedName = strings.TrimSpace(name)
+		trimmedValue = strings.TrimSpace(value)
+	}
+
+	// TODO (skaris): properties defiend https://w3c.github.io/correlation-context/, are currently
+	// just put as part of the value.
+	var trimmedValueWithProps strings.Builder
+	trimmedValueWithProps.WriteString(trimmedValue)
+	for _, prop := range valueAndProps[1:] {
+		trimmedValueWithProps.WriteRune(';')
+		trimmedValueWithProps.WriteString(prop)
+	}
+
+	return label.String(trimmedName, trimmedValueWithProps.String()), BaggageMember{
+		Value:      trimmedValue,
+		Properties: parseBaggageProperties(valueAndProps[1:]),
+	}, true
+}
+
+func hasSurroundingSpace(s string) bool {
+	return strings.TrimSpace(s) != s
+}
+
 // Fields returns the keys who's values are set with Inject.
 func (b Baggage) Fields() []string {
 	return []string{baggageHeader}
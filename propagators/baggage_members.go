@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+type baggageMembersKeyType struct{}
+
+var baggageMembersKey baggageMembersKeyType
+
+// BaggageProperty is a property of a baggage member, as defined by the
+// W3C Baggage specification. A property without a value has an empty
+// Value.
+type BaggageProperty struct {
+	Key   string
+	Value string
+}
+
+// BaggageMember is the value of a baggage member together with its
+// properties.
+type BaggageMember struct {
+	Value      string
+	Properties []BaggageProperty
+}
+
+// ContextWithBaggageMembers returns a copy of ctx holding the passed
+// baggage members, keyed by the keys of their baggage items. The
+// Baggage propagator injects the Properties of a member after the value
+// of the baggage item with the same key, as long as the item still has
+// the Value of the member. Any other value is escaped as a whole,
+// semicolons included.
+func ContextWithBaggageMembers(ctx context.Context, members map[string]BaggageMember) context.Context {
+	return context.WithValue(ctx, baggageMembersKey, members)
+}
+
+// BaggageMembersFromContext returns the baggage members of ctx, as set
+// with ContextWithBaggageMembers. The Baggage propagator sets the
+// members with properties it extracts, with their values stripped of
+// the properties.
+func BaggageMembersFromContext(ctx context.Context) map[string]BaggageMember {
+	members, _ := ctx.Value(baggageMembersKey).(map[string]BaggageMember)
+	return members
+}
+
+// encodeBaggageProperties returns the properties as they follow the
+// value of a member in the baggage header.
+func encodeBaggageProperties(props []BaggageProperty) string {
+	var b strings.Builder
+	for _, p := range props {
+		b.WriteByte(';')
+		b.WriteString(url.QueryEscape(strings.TrimSpace(p.Key)))
+		if p.Value != "" {
+			b.WriteByte('=')
+			b.WriteString(url.QueryEscape(strings.TrimSpace(p.Value)))
+		}
+	}
+	return b.String()
+}
+
+// parseBaggageProperties parses the properties following the value of
+// a member in the baggage header. Empty properties are skipped.
+func parseBaggageProperties(props []string) []BaggageProperty {
+	var parsed []BaggageProperty
+	for _, p := range props {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		var prop BaggageProperty
+		if idx := strings.IndexByte(p, '='); idx >= 0 {
+			prop = BaggageProperty{
+				Key:   unescapeBaggageProperty(p[:idx]),
+				Value: unescapeBaggageProperty(p[idx+1:]),
+			}
+		} else {
+			prop.Key = unescapeBaggageProperty(p)
+		}
+		parsed = append(parsed, prop)
+	}
+	return parsed
+}
+
+func unescapeBaggageProperty(s string) string {
+	s = strings.TrimSpace(s)
+	if unescaped, err := url.QueryUnescape(s); err == nil {
+		return unescaped
+	}
+	return s
+}
@@ -16,7 +16,9 @@ package propagators_test
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"testing"
 
@@ -175,6 +177,68 @@ func TestExtractInvalidDistributedContextFromHTTPReq(t *testing.T) {
 	}
 }
 
+func TestExtractBaggageEncoding(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		wantLenient map[label.Key]string
+		wantStrict  map[label.Key]string
+	}{
+		{
+			name:        "well-formed",
+			header:      "key1=val1,key2=val2;prop=1,key%2C3=val%203",
+			wantLenient: map[label.Key]string{"key1": "val1", "key2": "val2;prop=1", "key,3": "val 3"},
+			wantStrict:  map[label.Key]string{"key1": "val1", "key2": "val2;prop=1", "key,3": "val 3"},
+		},
+		{
+			name:        "whitespace around the separators",
+			header:      "key1 = val1 , key2=val2",
+			wantLenient: map[label.Key]string{"key1": "val1", "key2": "val2"},
+			wantStrict:  map[label.Key]string{},
+		},
+		{
+			name:        "member without a value",
+			header:      "key1=val1,key2,key3=val3",
+			wantLenient: map[label.Key]string{"key1": "val1", "key3": "val3"},
+			wantStrict:  map[label.Key]string{},
+		},
+		{
+			name:        "empty member",
+			header:      "key1=val1,,key3=val3",
+			wantLenient: map[label.Key]string{"key1": "val1", "key3": "val3"},
+			wantStrict:  map[label.Key]string{},
+		},
+		{
+			name:        "invalid percent-encoding",
+			header:      "key1=val1,key2=%zz",
+			wantLenient: map[label.Key]string{"key1": "val1"},
+			wantStrict:  map[label.Key]string{},
+		},
+		{
+			name:        "several equal signs",
+			header:      "key1=val1=x,key2=val2",
+			wantLenient: map[label.Key]string{"key1": "val1", "key2": "val2"},
+			wantStrict:  map[label.Key]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("otcorrelations", tt.header)
+
+			got := baggageOf(propagators.Baggage{}.Extract(context.Background(), header))
+			if diff := cmp.Diff(tt.wantLenient, got); diff != "" {
+				t.Errorf("lenient extraction differs (-want +got):\n%s", diff)
+			}
+			got = baggageOf(propagators.NewBaggage(propagators.WithStrictEncoding()).Extract(context.Background(), header))
+			if diff := cmp.Diff(tt.wantStrict, got); diff != "" {
+				t.Errorf("strict extraction differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestInjectBaggageToHTTPReq(t *testing.T) {
 	propagator := propagators.Baggage{}
 	tests := []struct {
@@ -199,6 +263,14 @@ func TestInjectBaggageToHTTPReq(t *testing.T) {
 			},
 			wantInHeader: []string{"key1=val1%2Cval2", "key2=val3%3D4"},
 		},
+		{
+			name: "values with properties",
+			kvs: []label.KeyValue{
+				label.String("key1", "val1;prop=1"),
+				label.String("key2", "val 2;flag;p,2=a b"),
+			},
+			wantInHeader: []string{"key1=val1%3Bprop%3D1", "key2=val+2%3Bflag%3Bp%2C2%3Da+b"},
+		},
 		{
 			name: "values of non-string types",
 			kvs: []label.KeyValue{
@@ -249,6 +321,51 @@ func TestInjectBaggageToHTTPReq(t *testing.T) {
 	}
 }
 
+func TestBaggageMembers(t *testing.T) {
+	propagator := propagators.Baggage{}
+	ctx := baggage.ContextWithMap(context.Background(), baggage.NewMap(baggage.MapUpdate{
+		MultiKV: []label.KeyValue{
+			label.String("key1", "val 1"),
+			label.String("key2", "a,b;c"),
+			label.String("key3", "changed"),
+		},
+	}))
+	ctx = propagators.ContextWithBaggageMembers(ctx, map[string]propagators.BaggageMember{
+		"key1": {Value: "val 1", Properties: []propagators.BaggageProperty{{Key: "ttl", Value: "6 0"}, {Key: "flag"}}},
+		"key3": {Value: "stale", Properties: []propagators.BaggageProperty{{Key: "ttl", Value: "1"}}},
+	})
+	header := http.Header{}
+	propagator.Inject(ctx, header)
+	got := strings.Split(header.Get("otcorrelations"), ",")
+	sort.Strings(got)
+	want := []string{"key1=val+1;ttl=6+0;flag", "key2=a%2Cb%3Bc", "key3=changed"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("Inject: -got +want %s", diff)
+	}
+
+	ctx = propagator.Extract(context.Background(), header)
+	wantKVs := []label.KeyValue{
+		label.String("key1", "val 1;ttl=6+0;flag"),
+		label.String("key2", "a,b;c"),
+		label.String("key3", "changed"),
+	}
+	if diff := cmp.Diff(baggage.MapFromContext(ctx).Len(), len(wantKVs)); diff != "" {
+		t.Errorf("Extract: -got +want %s", diff)
+	}
+	for _, kv := range wantKVs {
+		v, _ := baggage.MapFromContext(ctx).Value(kv.Key)
+		if diff := cmp.Diff(v, kv.Value, cmp.AllowUnexported(label.Value{})); diff != "" {
+			t.Errorf("Extract %s: -got +want %s", kv.Key, diff)
+		}
+	}
+	wantMembers := map[string]propagators.BaggageMember{
+		"key1": {Value: "val 1", Properties: []propagators.BaggageProperty{{Key: "ttl", Value: "6 0"}, {Key: "flag"}}},
+	}
+	if diff := cmp.Diff(propagators.BaggageMembersFromContext(ctx), wantMembers); diff != "" {
+		t.Errorf("BaggageMembersFromContext: -got +want %s", diff)
+	}
+}
+
 func TestBaggagePropagatorGetAllKeys(t *testing.T) {
 	var propagator propagators.Baggage
 	want := []string{"otcorrelations"}
@@ -257,3 +374,121 @@ func TestBaggagePropagatorGetAllKeys(t *testing.T) {
 		t.Errorf("GetAllKeys: -got +want %s", diff)
 	}
 }
+
+func TestInjectBaggageIntoPopulatedCarrier(t *testing.T) {
+	tests := []struct {
+		name       string
+		propagator propagators.Baggage
+		want       []string
+	}{
+		{
+			name:       "replace",
+			propagator: propagators.Baggage{},
+			want:       []string{"key2=new", "key3=val3"},
+		},
+		{
+			name:       "merge",
+			propagator: propagators.NewBaggage(propagators.WithMergeOnInject()),
+			want:       []string{"key1=val1", "key2=new", "key3=val3", "key4=val4;prop=1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("otcorrelations", "key1=val1, key2=old,key4=val4;prop=1")
+			ctx := baggage.ContextWithMap(context.Background(), baggage.NewMap(baggage.MapUpdate{
+				MultiKV: []label.KeyValue{
+					label.String("key2", "new"),
+					label.String("key3", "val3"),
+				},
+			}))
+			tt.propagator.Inject(ctx, header)
+
+			got := strings.Split(header.Get("otcorrelations"), ",")
+			sort.Strings(got)
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("Inject into populated carrier: -got +want %s", diff)
+			}
+		})
+	}
+}
+
+func TestInjectEmptyBaggageKeepsCarrier(t *testing.T) {
+	for _, propagator := range []propagators.Baggage{
+		{},
+		propagators.NewBaggage(propagators.WithMergeOnInject()),
+	} {
+		header := http.Header{}
+		header.Set("otcorrelations", "key1=val1")
+		propagator.Inject(context.Background(), header)
+		if got := header.Get("otcorrelations"); got != "key1=val1" {
+			t.Errorf("Inject of empty baggage changed the carrier to %q", got)
+		}
+	}
+}
+
+func TestInjectBaggageWithinLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		kvs         []label.KeyValue
+		wantMembers int
+		wantDropped []string
+	}{
+		{
+			name:        "too many members",
+			kvs:         baggageKVs(200, 1),
+			wantMembers: 180,
+		},
+		{
+			name: "too many bytes",
+			kvs: append(baggageKVs(10, 1),
+				label.String("big1", strings.Repeat("x", 5000)),
+				label.String("big2", strings.Repeat("y", 4000)),
+			),
+			wantMembers: 11,
+			wantDropped: []string{"big1"},
+		},
+		{
+			name:        "single oversized member",
+			kvs:         append(baggageKVs(3, 1), label.String("huge", strings.Repeat("z", 9000))),
+			wantMembers: 3,
+			wantDropped: []string{"huge"},
+		},
+		{
+			name:        "at the limits",
+			kvs:         baggageKVs(180, 1),
+			wantMembers: 180,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			ctx := baggage.ContextWithMap(context.Background(), baggage.NewMap(baggage.MapUpdate{MultiKV: tt.kvs}))
+			propagators.Baggage{}.Inject(ctx, header)
+
+			got := header.Get("otcorrelations")
+			if len(got) > 8192 {
+				t.Errorf("header has %d bytes, more than 8192", len(got))
+			}
+			members := strings.Split(got, ",")
+			if len(members) != tt.wantMembers {
+				t.Errorf("header has %d members, want %d", len(members), tt.wantMembers)
+			}
+			for _, key := range tt.wantDropped {
+				if strings.Contains(got, key+"=") {
+					t.Errorf("member %q was not dropped", key)
+				}
+			}
+		})
+	}
+}
+
+func baggageKVs(n, valueLen int) []label.KeyValue {
+	kvs := make([]label.KeyValue, 0, n)
+	for i := 0; i < n; i++ {
+		kvs = append(kvs, label.String(fmt.Sprintf("key%d", i), strings.Repeat("v", valueLen)))
+	}
+	return kvs
+}
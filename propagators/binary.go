@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/internal/baggage"
+	"go.opentelemetry.io/otel/label"
+)
+
+const (
+	binaryHeader  = "x-otel-context"
+	binaryVersion = 0
+	// binarySpanContextLen is the length of the encoded version, trace
+	// ID, span ID and trace flags.
+	binarySpanContextLen = 1 + 16 + 8 + 1
+)
+
+// BinarySource is the extraction source recorded by the Binary
+// propagator.
+const BinarySource = "binary"
+
+// Binary is a propagator that carries the span context and the baggage
+// in the single x-otel-context field, for carriers that only have one
+// opaque metadata slot, like some caches and queues.
+//
+// The value is the standard base64 encoding of a version byte, the
+// trace ID, the span ID, the trace flags and, for every baggage item,
+// the length of its key, the key, the length of its value and the
+// value, the lengths being encoded as unsigned varints. Extract ignores
+// a value that is truncated, corrupted or of an unknown version.
+type Binary struct{}
+
+var _ otel.TextMapPropagator = Binary{}
+
+// Inject sets the span context and the baggage of ctx into the carrier.
+// Nothing is set if ctx has neither a valid span context nor baggage.
+func (Binary) Inject(ctx context.Context, carrier otel.TextMapCarrier) {
+	sc := otel.SpanFromContext(ctx).SpanContext()
+	bag := baggage.MapFromContext(ctx)
+	if !sc.IsValid() && bag.Len() == 0 {
+		return
+	}
+	if !sc.IsValid() {
+		sc = otel.SpanContext{}
+	}
+	carrier.Set(binaryHeader, base64.StdEncoding.EncodeToString(encodeBinary(sc, bag)))
+}
+
+// Extract returns a copy of ctx with the span context and the baggage
+// from the carrier. The span context is set as the remote span context
+// if it is valid. ctx is returned unchanged if the carrier holds no
+// valid value.
+func (Binary) Extract(ctx context.Context, carrier otel.TextMapCarrier) context.Context {
+	value := getField(carrier, binaryHeader)
+	if value == "" {
+		return ctx
+	}
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return ctx
+	}
+	sc, kvs, ok := decodeBinary(data)
+	if !ok {
+		return ctx
+	}
+	if sc.IsValid() {
+		ctx = otel.ContextWithRemoteSpanContext(ctx, sc)
+		ctx = ContextWithExtractionSource(ctx, BinarySource)
+	}
+	if len(kvs) > 0 {
+		ctx = baggage.ContextWithMap(ctx, baggage.NewMap(baggage.MapUpdate{MultiKV: kvs}))
+	}
+	return ctx
+}
+
+// Fields returns the keys who's values are set with Inject.
+func (Binary) Fields() []string {
+	return []string{binaryHeader}
+}
+
+func encodeBinary(sc otel.SpanContext, bag baggage.Map) []byte {
+	data := make([]byte, 0, binarySpanContextLen)
+	data = append(data, binaryVersion)
+	data = append(data, sc.TraceID[:]...)
+	data = append(data, sc.SpanID[:]...)
+	data = append(data, sc.TraceFlags)
+	bag.Foreach(func(kv label.KeyValue) bool {
+		data = appendBinaryString(data, string(kv.Key))
+		data = appendBinaryString(data, kv.Value.Emit())
+		return true
+	})
+	return data
+}
+
+func appendBinaryString(data []byte, s string) []byte {
+	var l [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(l[:], uint64(len(s)))
+	data = append(data, l[:n]...)
+	return append(data, s...)
+}
+
+// decodeBinary decodes the span context and the baggage items. The
+// returned boolean is false if data is not a complete value of the
+// supported version.
+func decodeBinary(data []byte) (otel.SpanContext, []label.KeyValue, bool) {
+	var sc otel.SpanContext
+	if len(data) < binarySpanContextLen || data[0] != binaryVersion {
+		return sc, nil, false
+	}
+	data = data[1:]
+	data = data[copy(sc.TraceID[:], data):]
+	data = data[copy(sc.SpanID[:], data):]
+	sc.TraceFlags = data[0]
+	data = data[1:]
+
+	var kvs []label.KeyValue
+	for len(data) > 0 {
+		var key, value string
+		var ok bool
+		if key, data, ok = readBinaryString(data); !ok || key == "" {
+			return otel.SpanContext{}, nil, false
+		}
+		if value, data, ok = readBinaryString(data); !ok {
+			return otel.SpanContext{}, nil, false
+		}
+		kvs = append(kvs, label.String(key, value))
+	}
+	return sc, kvs, true
+}
+
+func readBinaryString(data []byte) (string, []byte, bool) {
+	l, n := binary.Uvarint(data)
+	if n <= 0 || l > uint64(len(data)-n) {
+		return "", nil, false
+	}
+	end := n + int(l)
+	return string(data[n:end]), data[end:], true
+}
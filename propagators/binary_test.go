@@ -0,0 +1,149 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators_test
+
+import (
+	"context"
+	"encoding/base64"
+	"math/rand"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/internal/baggage"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/propagators"
+)
+
+func binaryTestContext() (context.Context, otel.SpanContext) {
+	ctx, span := oteltest.NewTracerProvider().Tracer("").Start(context.Background(), "binary")
+	ctx = baggage.ContextWithMap(ctx, baggage.NewMap(baggage.MapUpdate{MultiKV: []label.KeyValue{
+		label.String("user", "bob"),
+		label.String("tenant", "acme, inc."),
+	}}))
+	return ctx, span.SpanContext()
+}
+
+func baggageOf(ctx context.Context) map[label.Key]string {
+	got := make(map[label.Key]string)
+	baggage.MapFromContext(ctx).Foreach(func(kv label.KeyValue) bool {
+		got[kv.Key] = kv.Value.Emit()
+		return true
+	})
+	return got
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	prop := propagators.Binary{}
+	if diff := cmp.Diff([]string{"x-otel-context"}, prop.Fields()); diff != "" {
+		t.Errorf("fields differ (-want +got):\n%s", diff)
+	}
+
+	ctx, sc := binaryTestContext()
+	header := http.Header{}
+	prop.Inject(ctx, header)
+	if len(header) != 1 {
+		t.Fatalf("got headers %v, want a single one", header)
+	}
+
+	extracted := prop.Extract(context.Background(), header)
+	if diff := cmp.Diff(sc, otel.RemoteSpanContextFromContext(extracted)); diff != "" {
+		t.Errorf("extracted span context differs (-want +got):\n%s", diff)
+	}
+	want := map[label.Key]string{"user": "bob", "tenant": "acme, inc."}
+	if diff := cmp.Diff(want, baggageOf(extracted)); diff != "" {
+		t.Errorf("extracted baggage differs (-want +got):\n%s", diff)
+	}
+	if got := propagators.ExtractionSource(extracted); got != propagators.BinarySource {
+		t.Errorf("got extraction source %q, want %q", got, propagators.BinarySource)
+	}
+}
+
+func TestBinaryInjectNothing(t *testing.T) {
+	header := http.Header{}
+	propagators.Binary{}.Inject(context.Background(), header)
+	if len(header) != 0 {
+		t.Errorf("got headers %v for an empty context, want none", header)
+	}
+}
+
+func TestBinaryExtractInvalid(t *testing.T) {
+	ctx, _ := binaryTestContext()
+	header := http.Header{}
+	propagators.Binary{}.Inject(ctx, header)
+	data, err := base64.StdEncoding.DecodeString(header.Get("x-otel-context"))
+	if err != nil {
+		t.Fatalf("failed to decode the injected value: %v", err)
+	}
+
+	unknownVersion := append([]byte{1}, data[1:]...)
+	for name, value := range map[string]string{
+		"not base64":      "not base64!",
+		"unknown version": base64.StdEncoding.EncodeToString(unknownVersion),
+		"too short":       base64.StdEncoding.EncodeToString(data[:20]),
+		"cut in an item":  base64.StdEncoding.EncodeToString(data[:len(data)-1]),
+	} {
+		t.Run(name, func(t *testing.T) {
+			parent := context.Background()
+			header := http.Header{}
+			header.Set("x-otel-context", value)
+			if got := (propagators.Binary{}).Extract(parent, header); got != parent {
+				t.Errorf("got a changed context for an invalid value")
+			}
+		})
+	}
+}
+
+func TestBinaryExtractCorrupted(t *testing.T) {
+	ctx, sc := binaryTestContext()
+	header := http.Header{}
+	propagators.Binary{}.Inject(ctx, header)
+	data, err := base64.StdEncoding.DecodeString(header.Get("x-otel-context"))
+	if err != nil {
+		t.Fatalf("failed to decode the injected value: %v", err)
+	}
+
+	extract := func(data []byte) context.Context {
+		header := http.Header{}
+		header.Set("x-otel-context", base64.StdEncoding.EncodeToString(data))
+		return propagators.Binary{}.Extract(context.Background(), header)
+	}
+
+	// Every truncation either is rejected or, when it happens to end
+	// between baggage items, keeps the span context intact.
+	for i := 0; i < len(data); i++ {
+		got := otel.RemoteSpanContextFromContext(extract(data[:i]))
+		if got.IsValid() && got != sc {
+			t.Errorf("got span context %v for a value truncated to %d bytes, want %v", got, i, sc)
+		}
+	}
+
+	// Random corruptions must never panic.
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		corrupted := append([]byte(nil), data...)
+		for j := r.Intn(4) + 1; j > 0; j-- {
+			corrupted[r.Intn(len(corrupted))] = byte(r.Intn(256))
+		}
+		extract(corrupted)
+
+		random := make([]byte, r.Intn(64))
+		r.Read(random)
+		extract(random)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+)
+
+// getField returns the value of the field with key in the carrier. If
+// the carrier has no value for key as given, the lowercase and the
+// canonical HTTP header forms of key are tried too, so the propagators
+// work with carriers normalizing their keys, like gRPC metadata, and
+// with carriers holding keys written by an http.Header.
+func getField(carrier otel.TextMapCarrier, key string) string {
+	if v := carrier.Get(key); v != "" {
+		return v
+	}
+	if lower := strings.ToLower(key); lower != key {
+		if v := carrier.Get(lower); v != "" {
+			return v
+		}
+	}
+	if canonical := http.CanonicalHeaderKey(key); canonical != key {
+		return carrier.Get(canonical)
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagators"
+)
+
+// canonicalCarrier is a carrier normalizing its keys to the canonical
+// HTTP header form on Set but looking them up as given on Get, like a
+// map filled from an http.Header.
+type canonicalCarrier map[string]string
+
+func (c canonicalCarrier) Get(key string) string        { return c[key] }
+func (c canonicalCarrier) Set(key string, value string) { c[http.CanonicalHeaderKey(key)] = value }
+
+func TestExtractNormalizedKeys(t *testing.T) {
+	ctx, sc := binaryTestContext()
+
+	testCases := []struct {
+		name        string
+		propagator  otel.TextMapPropagator
+		wantSC      otel.SpanContext
+		wantBaggage map[label.Key]string
+	}{
+		{
+			name:        "TraceContext",
+			propagator:  propagators.TraceContext{},
+			wantSC:      sc,
+			wantBaggage: map[label.Key]string{},
+		},
+		{
+			name:        "Baggage",
+			propagator:  propagators.Baggage{},
+			wantBaggage: baggageOf(ctx),
+		},
+		{
+			name:        "Binary",
+			propagator:  propagators.Binary{},
+			wantSC:      sc,
+			wantBaggage: baggageOf(ctx),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			carrier := canonicalCarrier{}
+			tc.propagator.Inject(ctx, carrier)
+			for k := range carrier {
+				if k == strings.ToLower(k) {
+					t.Fatalf("got lowercase key %q in the carrier", k)
+				}
+			}
+
+			got := tc.propagator.Extract(context.Background(), carrier)
+			if diff := cmp.Diff(tc.wantSC, otel.RemoteSpanContextFromContext(got)); diff != "" {
+				t.Errorf("extracted span context differs (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantBaggage, baggageOf(got)); diff != "" {
+				t.Errorf("extracted baggage differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+)
+
+// contextCarrierKey is the type of the keys of the values stored by a
+// ContextCarrier. Being unexported, it cannot collide with the context
+// keys of other packages.
+type contextCarrierKey string
+
+// ContextCarrier is a TextMapCarrier storing the values as context
+// values instead of headers. It propagates the trace context within a
+// process, for example to asynchronous work:
+//
+//	dst := context.Background()
+//	propagator.Inject(ctx, propagators.ContextCarrier{Ctx: &dst})
+//	go func() {
+//		ctx := propagator.Extract(context.Background(), propagators.ContextCarrier{Ctx: &dst})
+//		// ...
+//	}()
+//
+// The values are stored under keys of an unexported type, so they do
+// not collide with other values of the context.
+type ContextCarrier struct {
+	// Ctx points to the context the values are read from. Set replaces
+	// it with a child context holding the new value.
+	Ctx *context.Context
+}
+
+var _ otel.TextMapCarrier = ContextCarrier{}
+
+// Get returns the value stored with key by Set, or an empty string if
+// there is none.
+func (c ContextCarrier) Get(key string) string {
+	if c.Ctx == nil || *c.Ctx == nil {
+		return ""
+	}
+	value, _ := (*c.Ctx).Value(contextCarrierKey(key)).(string)
+	return value
+}
+
+// Set stores the value with key in the context pointed to by Ctx,
+// replacing any previous value with the same key. It does nothing if
+// Ctx is nil.
+func (c ContextCarrier) Set(key string, value string) {
+	if c.Ctx == nil {
+		return
+	}
+	ctx := *c.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	*c.Ctx = context.WithValue(ctx, contextCarrierKey(key), value)
+}
@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/propagators"
+)
+
+type collidingKey string
+
+func TestContextCarrier(t *testing.T) {
+	ctx, span := oteltest.NewTracerProvider().Tracer("").Start(context.Background(), "async")
+	sc := span.SpanContext()
+	prop := propagators.TraceContext{}
+
+	dst := context.WithValue(context.Background(), collidingKey("traceparent"), "unrelated")
+	prop.Inject(ctx, propagators.ContextCarrier{Ctx: &dst})
+
+	if got := dst.Value(collidingKey("traceparent")); got != "unrelated" {
+		t.Errorf("got colliding value %v, want it untouched", got)
+	}
+
+	done := make(chan otel.SpanContext)
+	go func() {
+		extracted := prop.Extract(context.Background(), propagators.ContextCarrier{Ctx: &dst})
+		done <- otel.RemoteSpanContextFromContext(extracted)
+	}()
+	if diff := cmp.Diff(sc, <-done); diff != "" {
+		t.Errorf("extracted span context differs (-want +got):\n%s", diff)
+	}
+
+	if got := (propagators.ContextCarrier{}).Get("traceparent"); got != "" {
+		t.Errorf("got %q from an empty carrier, want nothing", got)
+	}
+}
+
+func TestZeroContextCarrier(t *testing.T) {
+	ctx, _ := oteltest.NewTracerProvider().Tracer("").Start(context.Background(), "async")
+	var carrier propagators.ContextCarrier
+	propagators.TraceContext{}.Inject(ctx, carrier)
+	if got := carrier.Get("traceparent"); got != "" {
+		t.Errorf("got %q from a zero carrier after Inject, want nothing", got)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators
+
+import (
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+)
+
+// EnvCarrier is a TextMapCarrier storing the values under environment
+// variable names, for passing the trace context to a subprocess. A key
+// is turned into a variable name by uppercasing it and replacing every
+// character other than an ASCII letter or digit with an underscore, so
+// traceparent is stored as TRACEPARENT and x-otel-context as
+// X_OTEL_CONTEXT. Get applies the same transformation, so the values
+// injected into an EnvCarrier are extracted from it again.
+type EnvCarrier map[string]string
+
+var _ otel.TextMapCarrier = EnvCarrier{}
+
+// NewEnvCarrier returns an EnvCarrier holding the variables of env,
+// which is in the "key=value" form returned by os.Environ. Entries
+// without an equal sign are ignored and the last value of a variable
+// set multiple times is used, like os/exec does.
+func NewEnvCarrier(env []string) EnvCarrier {
+	c := make(EnvCarrier, len(env))
+	for _, kv := range env {
+		i := strings.IndexByte(kv, '=')
+		if i < 0 {
+			continue
+		}
+		c[kv[:i]] = kv[i+1:]
+	}
+	return c
+}
+
+// Get returns the value stored for key, or an empty string if there
+// is none.
+func (c EnvCarrier) Get(key string) string {
+	return c[envVarName(key)]
+}
+
+// Set stores the value for key, replacing any previous value.
+func (c EnvCarrier) Set(key string, value string) {
+	c[envVarName(key)] = value
+}
+
+// Environ returns the variables of c in the "key=value" form, sorted
+// by name. It is meant to be appended to the Env field of an exec.Cmd.
+func (c EnvCarrier) Environ() []string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	env := make([]string, 0, len(names))
+	for _, name := range names {
+		env = append(env, name+"="+c[name])
+	}
+	return env
+}
+
+// envVarName returns the environment variable name for key.
+func envVarName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return '_'
+		}
+	}, key)
+}
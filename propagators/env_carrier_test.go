@@ -0,0 +1,115 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators_test
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagators"
+)
+
+func TestEnvCarrier(t *testing.T) {
+	carrier := propagators.EnvCarrier{}
+	carrier.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	carrier.Set("x-otel-context", "value")
+	carrier.Set("ot.baggage/1", "other")
+
+	want := propagators.EnvCarrier{
+		"TRACEPARENT":    "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"X_OTEL_CONTEXT": "value",
+		"OT_BAGGAGE_1":   "other",
+	}
+	if diff := cmp.Diff(want, carrier); diff != "" {
+		t.Errorf("stored variables differ (-want +got):\n%s", diff)
+	}
+	for _, key := range []string{"traceparent", "TRACEPARENT", "Traceparent"} {
+		if got := carrier.Get(key); got != want["TRACEPARENT"] {
+			t.Errorf("got %q for key %q, want %q", got, key, want["TRACEPARENT"])
+		}
+	}
+	if got := carrier.Get("X-Otel-Context"); got != "value" {
+		t.Errorf("got %q for a dashed key, want %q", got, "value")
+	}
+
+	wantEnv := []string{
+		"OT_BAGGAGE_1=other",
+		"TRACEPARENT=00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"X_OTEL_CONTEXT=value",
+	}
+	if diff := cmp.Diff(wantEnv, carrier.Environ()); diff != "" {
+		t.Errorf("environment differs (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewEnvCarrier(t *testing.T) {
+	carrier := propagators.NewEnvCarrier([]string{
+		"PATH=/usr/bin",
+		"TRACESTATE=a=1,b=2",
+		"INVALID",
+		"EMPTY=",
+		"TRACEPARENT=first",
+		"TRACEPARENT=last",
+	})
+	want := propagators.EnvCarrier{
+		"PATH":        "/usr/bin",
+		"TRACESTATE":  "a=1,b=2",
+		"EMPTY":       "",
+		"TRACEPARENT": "last",
+	}
+	if diff := cmp.Diff(want, carrier); diff != "" {
+		t.Errorf("parsed variables differ (-want +got):\n%s", diff)
+	}
+}
+
+func TestEnvCarrierSubprocess(t *testing.T) {
+	ctx, sc := binaryTestContext()
+	prop := otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}, propagators.Binary{})
+
+	// The parent injects into the environment of the subprocess.
+	parent := propagators.EnvCarrier{}
+	prop.Inject(ctx, parent)
+	cmd := exec.Command("child")
+	cmd.Env = append([]string{"PATH=/usr/bin", "HOME=/home/user"}, parent.Environ()...)
+
+	for _, name := range []string{"TRACEPARENT", "OTCORRELATIONS", "X_OTEL_CONTEXT"} {
+		var found bool
+		for _, kv := range cmd.Env {
+			found = found || strings.HasPrefix(kv, name+"=")
+		}
+		if !found {
+			t.Errorf("got no %s variable in %q", name, cmd.Env)
+		}
+	}
+
+	// The subprocess extracts from its environment.
+	child := propagators.NewEnvCarrier(cmd.Env)
+	for _, p := range []otel.TextMapPropagator{prop, propagators.TraceContext{}, propagators.Binary{}} {
+		extracted := p.Extract(context.Background(), child)
+		got := otel.RemoteSpanContextFromContext(extracted)
+		if got.TraceID != sc.TraceID || got.SpanID != sc.SpanID {
+			t.Errorf("got span context %v from the environment, want %v", got, sc)
+		}
+	}
+	extracted := prop.Extract(context.Background(), child)
+	if diff := cmp.Diff(baggageOf(ctx), baggageOf(extracted)); diff != "" {
+		t.Errorf("extracted baggage differs (-want +got):\n%s", diff)
+	}
+}
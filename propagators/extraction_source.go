@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/internal/propagation"
+)
+
+// TraceContextSource is the extraction source recorded by the
+// TraceContext propagator.
+const TraceContextSource = "tracecontext"
+
+// BaggageSource is the extraction source recorded by the Baggage
+// propagator. It never replaces the source of a remote span context.
+const BaggageSource = "baggage"
+
+// ContextWithExtractionSource returns a copy of ctx recording source as
+// the propagator that extracted the remote span context of ctx. The
+// TraceContext propagator calls it when it extracts a span context.
+// Other propagators can call it to be reported by ExtractionSource too.
+//
+// In a composite propagator, every propagator extracts into the context
+// returned by the previous one, so the source of the last propagator
+// that extracted a span context is recorded, which is also the one
+// whose span context is used. The composite propagator records the
+// propagators that extract a span context without recording themselves
+// with the name of their type, like "propagators.B3".
+func ContextWithExtractionSource(ctx context.Context, source string) context.Context {
+	return propagation.ContextWithExtractionSource(ctx, source)
+}
+
+// ExtractionSource returns the propagator that extracted the remote
+// span context of ctx, as recorded by ContextWithExtractionSource. If
+// only baggage was extracted, BaggageSource is returned. An empty
+// string is returned if no source was recorded.
+func ExtractionSource(ctx context.Context) string {
+	return propagation.ExtractionSource(ctx)
+}
@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/internal/baggage"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/propagators"
+)
+
+// TestInjectTwice checks that injecting twice into the same carrier
+// sets the fields instead of appending to them, so a carrier reused
+// across retries does not end up with duplicate values.
+func TestInjectTwice(t *testing.T) {
+	extracted := propagators.TraceContext{}.Extract(context.Background(), http.Header{
+		"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		"Tracestate":  []string{"foo=bar,vendor=1"},
+	})
+	ctx, _ := oteltest.NewTracerProvider().Tracer("").Start(extracted, "inject")
+	// A single baggage item, the iteration order of several is random.
+	ctx = baggage.NewContext(ctx, label.String("user", "alice"))
+
+	testCases := []struct {
+		name       string
+		propagator otel.TextMapPropagator
+	}{
+		{name: "TraceContext", propagator: propagators.TraceContext{}},
+		{name: "Baggage", propagator: propagators.Baggage{}},
+		{name: "Binary", propagator: propagators.Binary{}},
+		{name: "Noop", propagator: propagators.Noop{}},
+		{
+			name: "composite",
+			propagator: otel.NewCompositeTextMapPropagator(
+				propagators.TraceContext{},
+				propagators.Baggage{},
+			),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			once := http.Header{}
+			tc.propagator.Inject(ctx, once)
+
+			twice := http.Header{}
+			tc.propagator.Inject(ctx, twice)
+			tc.propagator.Inject(ctx, twice)
+
+			if diff := cmp.Diff(once, twice); diff != "" {
+				t.Errorf("injecting twice differs from injecting once (-once +twice):\n%s", diff)
+			}
+			for k, v := range twice {
+				if len(v) != 1 {
+					t.Errorf("got %d values for %q, want 1", len(v), k)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
+)
+
+// Keys of the labels returned by LogAttrs.
+const (
+	LogTraceIDKey = label.Key("trace_id")
+	LogSpanIDKey  = label.Key("span_id")
+	LogSampledKey = label.Key("sampled")
+)
+
+// LogAttrs returns the trace ID, span ID and sampled flag of the
+// active span in ctx as labels, so they can be added to structured
+// log records to correlate them with traces. It returns nil if there
+// is no active span with a valid SpanContext.
+func LogAttrs(ctx context.Context) []label.KeyValue {
+	sc := otel.SpanFromContext(ctx).SpanContext()
+	if !sc.IsValid() {
+		return nil
+	}
+	return []label.KeyValue{
+		LogTraceIDKey.String(sc.TraceID.String()),
+		LogSpanIDKey.String(sc.SpanID.String()),
+		LogSampledKey.Bool(sc.IsSampled()),
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators
+
+import "go.opentelemetry.io/otel"
+
+// MapCarrier is a TextMapCarrier storing the values in a plain map, for
+// example to log the propagated values or to hand them over to another
+// system. Unlike an http.Header, the keys are used as is.
+type MapCarrier map[string]string
+
+var _ otel.TextMapCarrier = MapCarrier{}
+
+// Get returns the value stored with key, or an empty string if there
+// is none.
+func (c MapCarrier) Get(key string) string {
+	return c[key]
+}
+
+// Set stores the value with key, replacing any previous value.
+func (c MapCarrier) Set(key string, value string) {
+	c[key] = value
+}
@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/propagators"
+)
+
+func TestMapCarrier(t *testing.T) {
+	sc := otel.SpanContext{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: otel.FlagsSampled,
+	}
+	carrier := propagators.MapCarrier{}
+	carrier.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	extracted := propagators.TraceContext{}.Extract(context.Background(), carrier)
+	if diff := cmp.Diff(sc, otel.RemoteSpanContextFromContext(extracted)); diff != "" {
+		t.Errorf("extracted span context differs (-want +got):\n%s", diff)
+	}
+	if got := carrier.Get("Traceparent"); got != "" {
+		t.Errorf("got %q for a differently cased key, want nothing", got)
+	}
+
+	ctx, span := oteltest.NewTracerProvider().Tracer("").Start(extracted, "child")
+	carrier = propagators.MapCarrier{}
+	propagators.TraceContext{}.Inject(ctx, carrier)
+	want := propagators.MapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-" + span.SpanContext().SpanID.String() + "-01"}
+	if diff := cmp.Diff(want, carrier); diff != "" {
+		t.Errorf("injected values differ (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Noop is a propagator that propagates nothing. Inject leaves the
+// carrier untouched and Extract returns the passed context as is.
+//
+// It can replace a propagator, alone or in a composite propagator, to
+// disable propagation without changing how the propagators are wired,
+// for example in tests or behind a feature flag.
+type Noop struct{}
+
+var _ otel.TextMapPropagator = Noop{}
+
+// Inject does nothing.
+func (Noop) Inject(context.Context, otel.TextMapCarrier) {}
+
+// Extract returns ctx unchanged.
+func (Noop) Extract(ctx context.Context, _ otel.TextMapCarrier) context.Context {
+	return ctx
+}
+
+// Fields returns no keys, as Inject sets none.
+func (Noop) Fields() []string {
+	return nil
+}
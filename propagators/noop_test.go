@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagators"
+)
+
+func TestNoop(t *testing.T) {
+	prop := otel.NewCompositeTextMapPropagator(propagators.Noop{})
+	if fields := prop.Fields(); len(fields) != 0 {
+		t.Errorf("got fields %v, want none", fields)
+	}
+
+	sc := otel.SpanContext{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: otel.FlagsSampled,
+	}
+	ctx := otel.ContextWithRemoteSpanContext(context.Background(), sc)
+	header := http.Header{"Existing": []string{"value"}}
+	prop.Inject(ctx, header)
+	if diff := cmp.Diff(http.Header{"Existing": []string{"value"}}, header); diff != "" {
+		t.Errorf("Inject changed the carrier (-want +got):\n%s", diff)
+	}
+
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if got := prop.Extract(ctx, header); got != ctx {
+		t.Errorf("Extract returned a different context")
+	}
+}
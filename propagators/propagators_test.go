@@ -22,6 +22,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
 	"go.opentelemetry.io/otel/propagators"
 )
 
@@ -114,3 +116,22 @@ func TestMultiplePropagators(t *testing.T) {
 		assert.Truef(t, sc.IsValid(), "%#v clobbers span context", prop)
 	}
 }
+
+func TestLogAttrs(t *testing.T) {
+	assert.Nil(t, propagators.LogAttrs(context.Background()))
+
+	var id uint64
+	tracer := &oteltest.MockTracer{StartSpanID: &id}
+	ctx := otel.ContextWithRemoteSpanContext(context.Background(), otel.SpanContext{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: otel.FlagsSampled,
+	})
+	ctx, span := tracer.Start(ctx, "test")
+
+	assert.Equal(t, []label.KeyValue{
+		propagators.LogTraceIDKey.String(traceIDStr),
+		propagators.LogSpanIDKey.String(span.SpanContext().SpanID.String()),
+		propagators.LogSampledKey.Bool(true),
+	}, propagators.LogAttrs(ctx))
+}
@@ -17,10 +17,14 @@ package propagators
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"regexp"
+	"strings"
+	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/internal/trace/noop"
 )
 
 const (
@@ -28,14 +32,28 @@ const (
 	maxVersion        = 254
 	traceparentHeader = "traceparent"
 	tracestateHeader  = "tracestate"
+
+	// flagsRandom is the trace-flags bit marking a random trace ID.
+	flagsRandom byte = 0x02
+	// knownTraceFlags are the trace-flags bits defined by the W3C Trace
+	// Context specification.
+	knownTraceFlags = otel.FlagsSampled | flagsRandom
 )
 
 type traceContextPropagatorKeyType uint
 
 const (
-	tracestateKey traceContextPropagatorKeyType = 0
+	tracestateKey traceContextPropagatorKeyType = iota
+	traceparentKey
 )
 
+// preservedTraceParent is a traceparent header of a future version kept
+// by Extract for Inject to emit it unchanged.
+type preservedTraceParent struct {
+	header string
+	sc     otel.SpanContext
+}
+
 // TraceContext is a propagator that supports the W3C Trace Context format
 // (https://www.w3.org/TR/trace-context/)
 //
@@ -44,19 +62,226 @@ const (
 // to choose if they want to participate in a trace by modifying the
 // traceparent header and relevant parts of the tracestate header containing
 // their proprietary information.
-type TraceContext struct{}
+//
+// The zero value is ready to use. Use NewTraceContext to create a
+// TraceContext with non-default behavior. TraceContext values are
+// comparable, two of them are equal if they are both zero or were
+// copied from the same NewTraceContext call.
+type TraceContext struct {
+	// config is held by pointer to keep TraceContext comparable
+	// despite the func fields of traceContextConfig. Nil means the
+	// default configuration.
+	config *traceContextConfig
+}
 
 var _ otel.TextMapPropagator = TraceContext{}
 var traceCtxRegExp = regexp.MustCompile("^(?P<version>[0-9a-f]{2})-(?P<traceID>[a-f0-9]{32})-(?P<spanID>[a-f0-9]{16})-(?P<traceFlags>[a-f0-9]{2})(?:-.*)?$")
 
+type traceContextConfig struct {
+	// strictOutput normalizes the injected headers.
+	strictOutput bool
+	// clock returns the current time. Nil means time.Now.
+	clock func() time.Time
+	// newSpanID generates the span ID of the local span Extract
+	// continues the trace with. Nil means no local span is created.
+	newSpanID func() otel.SpanID
+	// preserveFutureVersions keeps traceparent headers of versions
+	// newer than the supported one for Inject.
+	preserveFutureVersions bool
+	// validate is called by Extract for every valid traceparent.
+	validate func(sc otel.SpanContext, tracestate string) error
+	// lenientHexCase makes Extract accept uppercase hex digits in the
+	// traceparent header.
+	lenientHexCase bool
+	// rejectUnknownFlags makes Extract reject a traceparent header with
+	// trace-flags bits outside knownTraceFlags set.
+	rejectUnknownFlags bool
+}
+
+// TraceContextOption applies an option to a TraceContext.
+type TraceContextOption interface {
+	Apply(*traceContextConfig)
+}
+
+// defaultTraceContextConfig is the configuration of a TraceContext
+// created without options. It must not be modified.
+var defaultTraceContextConfig traceContextConfig
+
+// NewTraceContext returns a TraceContext configured with options.
+func NewTraceContext(opts ...TraceContextOption) TraceContext {
+	if len(opts) == 0 {
+		return TraceContext{}
+	}
+	config := new(traceContextConfig)
+	for _, opt := range opts {
+		opt.Apply(config)
+	}
+	return TraceContext{config: config}
+}
+
+// options returns the configuration of tc.
+func (tc TraceContext) options() *traceContextConfig {
+	if tc.config == nil {
+		return &defaultTraceContextConfig
+	}
+	return tc.config
+}
+
+type strictOutputOption bool
+
+func (o strictOutputOption) Apply(c *traceContextConfig) {
+	c.strictOutput = bool(o)
+}
+
+// WithStrictOutput makes Inject normalize the tracestate header it
+// writes so it conforms to the W3C Trace Context specification
+// regardless of how the extracted value was formatted upstream.
+// Whitespace around the tracestate members and their keys and values
+// is trimmed and empty members are dropped. The members themselves
+// belong to their vendors and are not otherwise changed. The
+// traceparent header is always written in lowercase.
+func WithStrictOutput() TraceContextOption {
+	return strictOutputOption(true)
+}
+
+type clockOption func() time.Time
+
+func (o clockOption) Apply(c *traceContextConfig) {
+	c.clock = o
+}
+
+// WithClock sets the function the TraceContext uses to get the
+// current time. It defaults to time.Now and is meant to make time
+// dependent behavior deterministic in tests.
+func WithClock(clock func() time.Time) TraceContextOption {
+	return clockOption(clock)
+}
+
+func (tc TraceContext) now() time.Time {
+	if clock := tc.options().clock; clock != nil {
+		return clock()
+	}
+	return time.Now()
+}
+
+type continueAsNewSpanOption func() otel.SpanID
+
+func (o continueAsNewSpanOption) Apply(c *traceContextConfig) {
+	c.newSpanID = o
+}
+
+// WithContinueAsNewSpan makes Extract continue the extracted trace as a
+// new local span, so the extracting process, like a proxy, appears in
+// the trace. Next to the extracted remote parent, the returned context
+// holds a current span with the trace ID of the parent and a span ID
+// returned by idGen. The span only carries the SpanContext, it records
+// nothing.
+func WithContinueAsNewSpan(idGen func() otel.SpanID) TraceContextOption {
+	return continueAsNewSpanOption(idGen)
+}
+
+type preserveFutureVersionsOption bool
+
+func (o preserveFutureVersionsOption) Apply(c *traceContextConfig) {
+	c.preserveFutureVersions = bool(o)
+}
+
+// WithPreserveFutureVersions makes Inject emit an extracted traceparent
+// header of a version newer than the supported one verbatim, including
+// any trailing data, instead of rewriting it in the supported version
+// format. This keeps a passthrough proxy from downgrading newer
+// callers. The header is only preserved while the trace is forwarded
+// unchanged, that is while the injected span context is the extracted
+// one, or there is no current span and the remote span context is the
+// extracted one.
+func WithPreserveFutureVersions() TraceContextOption {
+	return preserveFutureVersionsOption(true)
+}
+
+type extractValidatorOption func(otel.SpanContext, string) error
+
+func (o extractValidatorOption) Apply(c *traceContextConfig) {
+	c.validate = o
+}
+
+// WithExtractValidator sets a function Extract calls with the span
+// context of every valid traceparent header and the raw tracestate
+// header, which is empty if the carrier has none. The function can log
+// or count hops violating a propagation policy, like a traceparent
+// without the tracestate of a known vendor. If it returns an error,
+// Extract rejects the headers and returns the passed context as is.
+func WithExtractValidator(validate func(sc otel.SpanContext, tracestate string) error) TraceContextOption {
+	return extractValidatorOption(validate)
+}
+
+type lenientHexCaseOption bool
+
+func (o lenientHexCaseOption) Apply(c *traceContextConfig) {
+	c.lenientHexCase = bool(o)
+}
+
+// WithLenientHexCase makes Extract accept a traceparent header with
+// uppercase hex digits, which the W3C Trace Context specification
+// forbids but some non-compliant upstreams send. The header is
+// lowercased before it is parsed, so a single misbehaving hop does not
+// break the trace. By default such a header is rejected.
+func WithLenientHexCase() TraceContextOption {
+	return lenientHexCaseOption(true)
+}
+
+type rejectUnknownFlagsOption bool
+
+func (o rejectUnknownFlagsOption) Apply(c *traceContextConfig) {
+	c.rejectUnknownFlags = bool(o)
+}
+
+// WithRejectUnknownFlags makes Extract reject a traceparent header of a
+// future version that sets any trace-flags bit other than the sampled
+// and random bits. By default the unknown bits of such a header are
+// masked off and the header is accepted. It does not change the
+// handling of version 00 headers, which are always rejected if they set
+// an unknown bit.
+func WithRejectUnknownFlags() TraceContextOption {
+	return rejectUnknownFlagsOption(true)
+}
+
+// continuedSpan is the span Extract puts in the context when configured
+// with WithContinueAsNewSpan.
+type continuedSpan struct {
+	otel.Span
+	sc otel.SpanContext
+}
+
+func (s continuedSpan) SpanContext() otel.SpanContext {
+	return s.sc
+}
+
 // Inject set tracecontext from the Context into the carrier.
+//
+// The headers are set with the carrier's Set method, so any
+// traceparent and tracestate values already present in the carrier
+// are replaced. An empty or blank tracestate is never injected.
 func (tc TraceContext) Inject(ctx context.Context, carrier otel.TextMapCarrier) {
+	config := tc.options()
 	tracestate := ctx.Value(tracestateKey)
 	if state, ok := tracestate.(string); tracestate != nil && ok {
-		carrier.Set(tracestateHeader, state)
+		if config.strictOutput {
+			state = normalizeTracestate(state)
+		}
+		if strings.TrimSpace(state) != "" {
+			carrier.Set(tracestateHeader, state)
+		}
 	}
 
 	sc := otel.SpanFromContext(ctx).SpanContext()
+	if config.preserveFutureVersions {
+		if p, ok := ctx.Value(traceparentKey).(preservedTraceParent); ok {
+			if sc == p.sc || (!sc.IsValid() && otel.RemoteSpanContextFromContext(ctx) == p.sc) {
+				carrier.Set(traceparentHeader, p.header)
+				return
+			}
+		}
+	}
 	if !sc.IsValid() {
 		return
 	}
@@ -68,89 +293,175 @@ func (tc TraceContext) Inject(ctx context.Context, carrier otel.TextMapCarrier)
 	carrier.Set(traceparentHeader, h)
 }
 
+// normalizeTracestate trims the whitespace around the tracestate list
+// members and their keys and values and drops empty members.
+func normalizeTracestate(state string) string {
+	members := strings.Split(state, ",")
+	normalized := members[:0]
+	for _, member := range members {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+		if idx := strings.IndexByte(member, '='); idx >= 0 {
+			key := strings.TrimSpace(member[:idx])
+			value := strings.TrimSpace(member[idx+1:])
+			member = key + "=" + value
+		}
+		normalized = append(normalized, member)
+	}
+	return strings.Join(normalized, ",")
+}
+
 // Extract reads tracecontext from the carrier into a returned Context.
+// The headers are looked up with their lowercase name and, if the
+// carrier has no value for it, with their canonical HTTP header name.
+//
+// If the carrier has no valid traceparent header, the remote span
+// context put in ctx by another propagator is kept. Otherwise the
+// extracted span context replaces it. So in a composite propagator
+// accepting both B3 and W3C headers, placing TraceContext last makes a
+// valid traceparent win over the B3 headers while an invalid one falls
+// back to them.
 func (tc TraceContext) Extract(ctx context.Context, carrier otel.TextMapCarrier) context.Context {
-	state := carrier.Get(tracestateHeader)
+	ctx, _ = tc.extractContext(ctx, carrier)
+	return ctx
+}
+
+// ExtractSampled works like Extract and also reports whether the
+// extracted span context is sampled, so middleware can skip expensive
+// instrumentation for unsampled requests early. It returns false if
+// the carrier has no valid traceparent header, whatever the remote
+// span context already in ctx.
+func (tc TraceContext) ExtractSampled(ctx context.Context, carrier otel.TextMapCarrier) (context.Context, bool) {
+	ctx, sc := tc.extractContext(ctx, carrier)
+	return ctx, sc.IsSampled()
+}
+
+// extractContext implements Extract. It also returns the extracted span
+// context, which is invalid if none was extracted.
+func (tc TraceContext) extractContext(ctx context.Context, carrier otel.TextMapCarrier) (context.Context, otel.SpanContext) {
+	config := tc.options()
+	state := getField(carrier, tracestateHeader)
+	sc, version := tc.extract(carrier)
+	if sc.IsValid() && config.validate != nil {
+		if err := config.validate(sc, state); err != nil {
+			return ctx, otel.SpanContext{}
+		}
+	}
 	if state != "" {
-		ctx = context.WithValue(ctx, tracestateKey, state)
+		ctx = ContextWithTracestate(ctx, state)
 	}
-
-	sc := tc.extract(carrier)
 	if !sc.IsValid() {
-		return ctx
+		return ctx, otel.SpanContext{}
+	}
+	if config.preserveFutureVersions && version > supportedVersion {
+		ctx = context.WithValue(ctx, traceparentKey, preservedTraceParent{
+			header: getField(carrier, traceparentHeader),
+			sc:     sc,
+		})
 	}
-	return otel.ContextWithRemoteSpanContext(ctx, sc)
+	ctx = otel.ContextWithRemoteSpanContext(ctx, sc)
+	ctx = ContextWithExtractionSource(ctx, TraceContextSource)
+	if config.newSpanID != nil {
+		local := sc
+		local.SpanID = config.newSpanID()
+		ctx = otel.ContextWithSpan(ctx, continuedSpan{Span: noop.Span, sc: local})
+	}
+	return ctx, sc
 }
 
-func (tc TraceContext) extract(carrier otel.TextMapCarrier) otel.SpanContext {
-	h := carrier.Get(traceparentHeader)
+// extract returns the span context of the traceparent header in the
+// carrier and the version of the header.
+func (tc TraceContext) extract(carrier otel.TextMapCarrier) (otel.SpanContext, int) {
+	config := tc.options()
+	h := getField(carrier, traceparentHeader)
 	if h == "" {
-		return otel.SpanContext{}
+		return otel.SpanContext{}, 0
+	}
+	if config.lenientHexCase {
+		h = strings.ToLower(h)
 	}
 
-	matches := traceCtxRegExp.FindStringSubmatch(h)
+	version, traceID, spanID, flags, err := ParseTraceParent(h)
+	if err != nil {
+		return otel.SpanContext{}, 0
+	}
+	if config.rejectUnknownFlags && flags&^knownTraceFlags != 0 {
+		return otel.SpanContext{}, 0
+	}
 
-	if len(matches) == 0 {
-		return otel.SpanContext{}
+	sc := otel.SpanContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+		// Clear all flags other than the trace-context supported sampling bit.
+		TraceFlags: flags & otel.FlagsSampled,
+	}
+	if !sc.IsValid() {
+		return otel.SpanContext{}, 0
 	}
 
+	return sc, version
+}
+
+// ErrInvalidTraceParent is returned by ParseTraceParent for a header
+// that does not conform to the W3C Trace Context specification. The
+// returned errors wrap it with details about the problem.
+var ErrInvalidTraceParent = errors.New("invalid traceparent header")
+
+// ParseTraceParent parses and validates the value of a W3C traceparent
+// header. The returned flags are the raw trace flags of the header,
+// including the bits not supported by the TraceContext propagator.
+func ParseTraceParent(header string) (version int, traceID otel.TraceID, spanID otel.SpanID, flags byte, err error) {
+	matches := traceCtxRegExp.FindStringSubmatch(header)
+
 	if len(matches) < 5 { // four subgroups plus the overall match
-		return otel.SpanContext{}
+		return 0, otel.TraceID{}, otel.SpanID{}, 0, fmt.Errorf("%w: malformed header %q", ErrInvalidTraceParent, header)
 	}
 
-	if len(matches[1]) != 2 {
-		return otel.SpanContext{}
-	}
 	ver, err := hex.DecodeString(matches[1])
-	if err != nil {
-		return otel.SpanContext{}
+	if err != nil || len(ver) != 1 {
+		return 0, otel.TraceID{}, otel.SpanID{}, 0, fmt.Errorf("%w: invalid version %q", ErrInvalidTraceParent, matches[1])
 	}
-	version := int(ver[0])
+	version = int(ver[0])
 	if version > maxVersion {
-		return otel.SpanContext{}
-	}
-
-	if version == 0 && len(matches) != 5 { // four subgroups plus the overall match
-		return otel.SpanContext{}
+		return 0, otel.TraceID{}, otel.SpanID{}, 0, fmt.Errorf("%w: invalid version %d", ErrInvalidTraceParent, version)
 	}
 
-	if len(matches[2]) != 32 {
-		return otel.SpanContext{}
-	}
-
-	var sc otel.SpanContext
-
-	sc.TraceID, err = otel.TraceIDFromHex(matches[2][:32])
+	traceID, err = otel.TraceIDFromHex(matches[2])
 	if err != nil {
-		return otel.SpanContext{}
+		return 0, otel.TraceID{}, otel.SpanID{}, 0, fmt.Errorf("%w: invalid trace ID: %v", ErrInvalidTraceParent, err)
 	}
 
-	if len(matches[3]) != 16 {
-		return otel.SpanContext{}
-	}
-	sc.SpanID, err = otel.SpanIDFromHex(matches[3])
+	spanID, err = otel.SpanIDFromHex(matches[3])
 	if err != nil {
-		return otel.SpanContext{}
+		return 0, otel.TraceID{}, otel.SpanID{}, 0, fmt.Errorf("%w: invalid span ID: %v", ErrInvalidTraceParent, err)
 	}
 
-	if len(matches[4]) != 2 {
-		return otel.SpanContext{}
-	}
 	opts, err := hex.DecodeString(matches[4])
-	if err != nil || len(opts) < 1 || (version == 0 && opts[0] > 2) {
-		return otel.SpanContext{}
-	}
-	// Clear all flags other than the trace-context supported sampling bit.
-	sc.TraceFlags = opts[0] & otel.FlagsSampled
-
-	if !sc.IsValid() {
-		return otel.SpanContext{}
+	if err != nil || len(opts) != 1 || (version == 0 && opts[0] > 2) {
+		return 0, otel.TraceID{}, otel.SpanID{}, 0, fmt.Errorf("%w: invalid trace flags %q", ErrInvalidTraceParent, matches[4])
 	}
 
-	return sc
+	return version, traceID, spanID, opts[0], nil
 }
 
 // Fields returns the keys who's values are set with Inject.
 func (tc TraceContext) Fields() []string {
 	return []string{traceparentHeader, tracestateHeader}
 }
+
+// ContextWithTracestate returns a copy of ctx holding the passed W3C
+// tracestate, which the TraceContext propagator injects next to the
+// span context of ctx.
+func ContextWithTracestate(ctx context.Context, state string) context.Context {
+	return context.WithValue(ctx, tracestateKey, state)
+}
+
+// TracestateFromContext returns the W3C tracestate the TraceContext
+// propagator extracted into ctx, or that was set with
+// ContextWithTracestate. An empty string is returned if ctx has none.
+func TracestateFromContext(ctx context.Context) string {
+	state, _ := ctx.Value(tracestateKey).(string)
+	return state
+}
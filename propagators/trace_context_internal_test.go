@@ -0,0 +1,33 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagators
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTraceContextClock(t *testing.T) {
+	before := time.Now()
+	if got := (TraceContext{}).now(); got.Before(before) {
+		t.Errorf("default clock returned %v, which is before %v", got, before)
+	}
+
+	fixed := time.Date(2020, time.October, 1, 0, 0, 0, 0, time.UTC)
+	tc := NewTraceContext(WithClock(func() time.Time { return fixed }))
+	if got := tc.now(); !got.Equal(fixed) {
+		t.Errorf("configured clock returned %v, want %v", got, fixed)
+	}
+}
@@ -16,8 +16,11 @@ package propagators_test
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -279,6 +282,44 @@ func TestTraceContextPropagator_GetAllKeys(t *testing.T) {
 	}
 }
 
+func TestTraceContextComparable(t *testing.T) {
+	if propagators.NewTraceContext() != (propagators.TraceContext{}) {
+		t.Errorf("TraceContext created without options differs from the zero value")
+	}
+
+	testCases := []struct {
+		name string
+		opts []propagators.TraceContextOption
+	}{
+		{
+			name: "WithClock",
+			opts: []propagators.TraceContextOption{propagators.WithClock(time.Now)},
+		},
+		{
+			name: "WithContinueAsNewSpan",
+			opts: []propagators.TraceContextOption{propagators.WithContinueAsNewSpan(func() otel.SpanID { return otel.SpanID{1} })},
+		},
+		{
+			name: "WithExtractValidator",
+			opts: []propagators.TraceContextOption{propagators.WithExtractValidator(func(otel.SpanContext, string) error { return nil })},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prop := propagators.NewTraceContext(tc.opts...)
+			if cp := prop; cp != prop {
+				t.Errorf("copy of TraceContext is not equal to the original")
+			}
+			// Comparing interfaces panics if the dynamic type is
+			// not comparable.
+			var a, b otel.TextMapPropagator = prop, propagators.TraceContext{}
+			if a == b {
+				t.Errorf("configured TraceContext is equal to the zero value")
+			}
+		})
+	}
+}
+
 func TestTraceStatePropagation(t *testing.T) {
 	prop := propagators.TraceContext{}
 	want := "opaquevalue"
@@ -294,4 +335,615 @@ func TestTraceStatePropagation(t *testing.T) {
 	if diff := cmp.Diff(outReq.Header.Get(headerName), want); diff != "" {
 		t.Errorf("Propagate tracestate: -got +want %s", diff)
 	}
+	if diff := cmp.Diff(propagators.TracestateFromContext(ctx), want); diff != "" {
+		t.Errorf("TracestateFromContext: -got +want %s", diff)
+	}
+
+	outReq.Header = http.Header{}
+	prop.Inject(propagators.ContextWithTracestate(context.Background(), "vendor=set"), outReq.Header)
+	if diff := cmp.Diff(outReq.Header.Get(headerName), "vendor=set"); diff != "" {
+		t.Errorf("Propagate ContextWithTracestate: -got +want %s", diff)
+	}
+}
+
+func TestTraceContextStrictOutput(t *testing.T) {
+	tests := []struct {
+		name       string
+		tracestate string
+		want       string
+	}{
+		{
+			name:       "already normalized",
+			tracestate: "foo=bar,baz=qux",
+			want:       "foo=bar,baz=qux",
+		},
+		{
+			name:       "mixed case kept",
+			tracestate: "Foo=Bar,BAZ=Qux",
+			want:       "Foo=Bar,BAZ=Qux",
+		},
+		{
+			name:       "whitespace around members",
+			tracestate: " foo = bar ,\tbaz=qux\t",
+			want:       "foo=bar,baz=qux",
+		},
+		{
+			name:       "empty members",
+			tracestate: "foo=bar,, ,baz=qux",
+			want:       "foo=bar,baz=qux",
+		},
+	}
+
+	header := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := http.Header{}
+			in.Set("traceparent", header)
+			in.Set("tracestate", tt.tracestate)
+
+			strict := propagators.NewTraceContext(propagators.WithStrictOutput())
+			out := http.Header{}
+			strict.Inject(strict.Extract(context.Background(), in), out)
+			if diff := cmp.Diff(out.Get("tracestate"), tt.want); diff != "" {
+				t.Errorf("strict tracestate: -got +want %s", diff)
+			}
+
+			var lax propagators.TraceContext
+			out = http.Header{}
+			lax.Inject(lax.Extract(context.Background(), in), out)
+			if diff := cmp.Diff(out.Get("tracestate"), tt.tracestate); diff != "" {
+				t.Errorf("default tracestate: -got +want %s", diff)
+			}
+		})
+	}
+}
+
+func TestParseTraceParent(t *testing.T) {
+	version, gotTraceID, gotSpanID, flags, err := propagators.ParseTraceParent("02-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-09")
+	if err != nil {
+		t.Fatalf("ParseTraceParent returned an error: %v", err)
+	}
+	if version != 2 {
+		t.Errorf("got version %d, want 2", version)
+	}
+	if gotTraceID != traceID {
+		t.Errorf("got trace ID %s, want %s", gotTraceID, traceID)
+	}
+	if gotSpanID != spanID {
+		t.Errorf("got span ID %s, want %s", gotSpanID, spanID)
+	}
+	if flags != 0x09 {
+		t.Errorf("got flags %#x, want %#x", flags, 0x09)
+	}
+
+	for _, header := range []string{
+		"",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-09",
+	} {
+		if _, _, _, _, err := propagators.ParseTraceParent(header); !errors.Is(err, propagators.ErrInvalidTraceParent) {
+			t.Errorf("ParseTraceParent(%q) returned %v, want ErrInvalidTraceParent", header, err)
+		}
+	}
+}
+
+func TestTraceContextContinueAsNewSpan(t *testing.T) {
+	newSpanID := otel.SpanID{0, 0, 0, 0, 0, 0, 0, 42}
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	prop := propagators.NewTraceContext(propagators.WithContinueAsNewSpan(func() otel.SpanID { return newSpanID }))
+	ctx := prop.Extract(context.Background(), header)
+
+	wantRemote := otel.SpanContext{TraceID: traceID, SpanID: spanID, TraceFlags: otel.FlagsSampled}
+	if diff := cmp.Diff(otel.RemoteSpanContextFromContext(ctx), wantRemote); diff != "" {
+		t.Errorf("remote span context: -got +want %s", diff)
+	}
+	wantLocal := otel.SpanContext{TraceID: traceID, SpanID: newSpanID, TraceFlags: otel.FlagsSampled}
+	if diff := cmp.Diff(otel.SpanFromContext(ctx).SpanContext(), wantLocal); diff != "" {
+		t.Errorf("local span context: -got +want %s", diff)
+	}
+
+	out := http.Header{}
+	prop.Inject(ctx, out)
+	if got, want := out.Get("traceparent"), "00-4bf92f3577b34da6a3ce929d0e0e4736-000000000000002a-01"; got != want {
+		t.Errorf("got injected traceparent %q, want %q", got, want)
+	}
+
+	ctx = propagators.TraceContext{}.Extract(context.Background(), header)
+	if otel.SpanFromContext(ctx).SpanContext().IsValid() {
+		t.Error("default TraceContext created a local span")
+	}
+}
+
+// b3SingleHeader is a minimal extractor of the single b3 header in the
+// {TraceId}-{SpanId}-{SamplingState} form.
+type b3SingleHeader struct{}
+
+func (b3SingleHeader) Inject(context.Context, otel.TextMapCarrier) {}
+
+func (b3SingleHeader) Extract(ctx context.Context, carrier otel.TextMapCarrier) context.Context {
+	parts := strings.Split(carrier.Get("b3"), "-")
+	if len(parts) != 3 {
+		return ctx
+	}
+	traceID, err := otel.TraceIDFromHex(parts[0])
+	if err != nil {
+		return ctx
+	}
+	spanID, err := otel.SpanIDFromHex(parts[1])
+	if err != nil {
+		return ctx
+	}
+	sc := otel.SpanContext{TraceID: traceID, SpanID: spanID}
+	if parts[2] == "1" {
+		sc.TraceFlags = otel.FlagsSampled
+	}
+	ctx = otel.ContextWithRemoteSpanContext(ctx, sc)
+	return propagators.ContextWithExtractionSource(ctx, "b3")
+}
+
+func (b3SingleHeader) Fields() []string { return []string{"b3"} }
+
+// fixedRemote extracts a fixed remote span context without recording
+// itself as the extraction source.
+type fixedRemote struct{}
+
+func (fixedRemote) Inject(context.Context, otel.TextMapCarrier) {}
+
+func (fixedRemote) Extract(ctx context.Context, _ otel.TextMapCarrier) context.Context {
+	return otel.ContextWithRemoteSpanContext(ctx, otel.SpanContext{
+		TraceID: otel.TraceID{0x01},
+		SpanID:  otel.SpanID{0x01},
+	})
+}
+
+func (fixedRemote) Fields() []string { return nil }
+
+func TestTraceContextWithB3Fallback(t *testing.T) {
+	w3cSC := otel.SpanContext{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: otel.FlagsSampled,
+	}
+	b3SC := otel.SpanContext{
+		TraceID: otel.TraceID{0xa1, 0xb2, 0xc3, 0xd4, 0xe5, 0xf6, 0x07, 0x18, 0x29, 0x3a, 0x4b, 0x5c, 0x6d, 0x7e, 0x8f, 0x90},
+		SpanID:  otel.SpanID{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef},
+	}
+	tests := []struct {
+		name        string
+		prop        otel.TextMapPropagator
+		traceparent string
+		want        otel.SpanContext
+	}{
+		{
+			name:        "TraceContext last wins",
+			prop:        otel.NewCompositeTextMapPropagator(b3SingleHeader{}, propagators.TraceContext{}),
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			want:        w3cSC,
+		},
+		{
+			name:        "B3 last wins",
+			prop:        otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, b3SingleHeader{}),
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			want:        b3SC,
+		},
+		{
+			name:        "invalid traceparent falls back to B3",
+			prop:        otel.NewCompositeTextMapPropagator(b3SingleHeader{}, propagators.TraceContext{}),
+			traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			want:        b3SC,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("traceparent", tt.traceparent)
+			header.Set("b3", "a1b2c3d4e5f60718293a4b5c6d7e8f90-0123456789abcdef-0")
+
+			ctx := tt.prop.Extract(context.Background(), header)
+			got := otel.RemoteSpanContextFromContext(ctx)
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("Extract: -got +want %s", diff)
+			}
+		})
+	}
+}
+
+func TestTraceContextPreserveFutureVersions(t *testing.T) {
+	future := "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future"
+	tests := []struct {
+		name   string
+		prop   propagators.TraceContext
+		header string
+		child  bool
+		want   string
+	}{
+		{
+			name:   "future version preserved",
+			prop:   propagators.NewTraceContext(propagators.WithPreserveFutureVersions()),
+			header: future,
+			want:   future,
+		},
+		{
+			name:   "future version normalized by default",
+			prop:   propagators.TraceContext{},
+			header: future,
+			want:   "",
+		},
+		{
+			name:   "supported version normalized",
+			prop:   propagators.NewTraceContext(propagators.WithPreserveFutureVersions()),
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			want:   "",
+		},
+		{
+			name:   "future version of a parent normalized",
+			prop:   propagators.NewTraceContext(propagators.WithPreserveFutureVersions()),
+			header: future,
+			child:  true,
+			want:   "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000002-01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := http.Header{}
+			in.Set("traceparent", tt.header)
+			ctx := tt.prop.Extract(context.Background(), in)
+			if tt.child {
+				ctx, _ = oteltest.NewTracerProvider().Tracer("").Start(ctx, "child")
+			}
+
+			out := http.Header{}
+			tt.prop.Inject(ctx, out)
+			if diff := cmp.Diff(out.Get("traceparent"), tt.want); diff != "" {
+				t.Errorf("Inject: -got +want %s", diff)
+			}
+		})
+	}
+}
+
+func TestTraceContextExtractValidator(t *testing.T) {
+	errMissingVendor := errors.New("missing vendor tracestate")
+	var calls []string
+	prop := propagators.NewTraceContext(propagators.WithExtractValidator(func(sc otel.SpanContext, tracestate string) error {
+		calls = append(calls, sc.SpanID.String()+" "+tracestate)
+		if !strings.Contains(tracestate, "vendor=") {
+			return errMissingVendor
+		}
+		return nil
+	}))
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name       string
+		header     http.Header
+		wantValid  bool
+		wantCalls  []string
+		wantInject string
+	}{
+		{
+			name:       "accepted",
+			header:     http.Header{"Traceparent": {traceparent}, "Tracestate": {"vendor=1"}},
+			wantValid:  true,
+			wantCalls:  []string{"00f067aa0ba902b7 vendor=1"},
+			wantInject: "vendor=1",
+		},
+		{
+			name:      "rejected without tracestate",
+			header:    http.Header{"Traceparent": {traceparent}},
+			wantCalls: []string{"00f067aa0ba902b7 "},
+		},
+		{
+			name:      "rejected with other tracestate",
+			header:    http.Header{"Traceparent": {traceparent}, "Tracestate": {"other=1"}},
+			wantCalls: []string{"00f067aa0ba902b7 other=1"},
+		},
+		{
+			name:       "not called without traceparent",
+			header:     http.Header{"Tracestate": {"other=1"}},
+			wantInject: "other=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			ctx := prop.Extract(context.Background(), tt.header)
+			if got := otel.RemoteSpanContextFromContext(ctx).IsValid(); got != tt.wantValid {
+				t.Errorf("got valid remote span context: %v, want %v", got, tt.wantValid)
+			}
+			if diff := cmp.Diff(calls, tt.wantCalls); diff != "" {
+				t.Errorf("validator calls: -got +want %s", diff)
+			}
+			out := http.Header{}
+			prop.Inject(ctx, out)
+			if got := out.Get("tracestate"); got != tt.wantInject {
+				t.Errorf("got injected tracestate %q, want %q", got, tt.wantInject)
+			}
+		})
+	}
+}
+
+func TestTraceContextLenientHexCase(t *testing.T) {
+	want := otel.SpanContext{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: otel.FlagsSampled,
+	}
+	tests := []struct {
+		name        string
+		traceparent string
+	}{
+		{
+			name:        "uppercase trace ID",
+			traceparent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		},
+		{
+			name:        "uppercase span ID",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00F067AA0BA902B7-01",
+		},
+		{
+			name:        "uppercase header",
+			traceparent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("traceparent", tt.traceparent)
+
+			ctx := propagators.TraceContext{}.Extract(context.Background(), header)
+			if got := otel.RemoteSpanContextFromContext(ctx); got.IsValid() {
+				t.Errorf("got span context %v by default, want none", got)
+			}
+
+			ctx = propagators.NewTraceContext(propagators.WithLenientHexCase()).Extract(context.Background(), header)
+			if diff := cmp.Diff(want, otel.RemoteSpanContextFromContext(ctx)); diff != "" {
+				t.Errorf("extracted span context differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTraceContextRejectUnknownFlags(t *testing.T) {
+	sampled := otel.SpanContext{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: otel.FlagsSampled,
+	}
+	notSampled := otel.SpanContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}
+	tests := []struct {
+		name         string
+		traceparent  string
+		wantDefault  otel.SpanContext
+		wantRejected otel.SpanContext
+	}{
+		{
+			name:         "sampled",
+			traceparent:  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantDefault:  sampled,
+			wantRejected: sampled,
+		},
+		{
+			name:         "future version sampled and random",
+			traceparent:  "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-03",
+			wantDefault:  sampled,
+			wantRejected: sampled,
+		},
+		{
+			name:         "future version unknown bits not sampled",
+			traceparent:  "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-fe",
+			wantDefault:  notSampled,
+			wantRejected: otel.SpanContext{},
+		},
+		{
+			name:         "future version all bits",
+			traceparent:  "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-ff",
+			wantDefault:  sampled,
+			wantRejected: otel.SpanContext{},
+		},
+		{
+			name:         "future version unknown bit sampled",
+			traceparent:  "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-09",
+			wantDefault:  sampled,
+			wantRejected: otel.SpanContext{},
+		},
+		{
+			name:         "version 00 unknown bit",
+			traceparent:  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-09",
+			wantDefault:  otel.SpanContext{},
+			wantRejected: otel.SpanContext{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("traceparent", tt.traceparent)
+
+			ctx := propagators.TraceContext{}.Extract(context.Background(), header)
+			if diff := cmp.Diff(tt.wantDefault, otel.RemoteSpanContextFromContext(ctx)); diff != "" {
+				t.Errorf("extracted span context by default differs (-want +got):\n%s", diff)
+			}
+
+			ctx = propagators.NewTraceContext(propagators.WithRejectUnknownFlags()).Extract(context.Background(), header)
+			if diff := cmp.Diff(tt.wantRejected, otel.RemoteSpanContextFromContext(ctx)); diff != "" {
+				t.Errorf("extracted span context with rejection differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExtractionSource(t *testing.T) {
+	prop := otel.NewCompositeTextMapPropagator(b3SingleHeader{}, propagators.TraceContext{})
+	tests := []struct {
+		name        string
+		traceparent string
+		want        string
+	}{
+		{
+			name:        "extracted by TraceContext",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			want:        propagators.TraceContextSource,
+		},
+		{
+			name:        "invalid traceparent",
+			traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			want:        "b3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("traceparent", tt.traceparent)
+			header.Set("b3", "a1b2c3d4e5f60718293a4b5c6d7e8f90-0123456789abcdef-0")
+
+			ctx := prop.Extract(context.Background(), header)
+			if got := propagators.ExtractionSource(ctx); got != tt.want {
+				t.Errorf("got extraction source %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := propagators.ExtractionSource(context.Background()); got != "" {
+		t.Errorf("got extraction source %q without extraction, want none", got)
+	}
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	header.Set("otcorrelations", "key=value")
+	composites := []struct {
+		name string
+		prop otel.TextMapPropagator
+		want string
+	}{
+		{
+			name: "unrecorded propagator last",
+			prop: otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, fixedRemote{}),
+			want: "propagators_test.fixedRemote",
+		},
+		{
+			name: "unrecorded propagator first",
+			prop: otel.NewCompositeTextMapPropagator(fixedRemote{}, propagators.TraceContext{}),
+			want: propagators.TraceContextSource,
+		},
+		{
+			name: "baggage after trace context",
+			prop: otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}),
+			want: propagators.TraceContextSource,
+		},
+		{
+			name: "baggage before trace context",
+			prop: otel.NewCompositeTextMapPropagator(propagators.Baggage{}, propagators.TraceContext{}),
+			want: propagators.TraceContextSource,
+		},
+		{
+			name: "baggage only",
+			prop: otel.NewCompositeTextMapPropagator(propagators.Baggage{}),
+			want: propagators.BaggageSource,
+		},
+	}
+	for _, tt := range composites {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.prop.Extract(context.Background(), header)
+			if got := propagators.ExtractionSource(ctx); got != tt.want {
+				t.Errorf("got extraction source %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceContextExtractSampled(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		wantSC      otel.SpanContext
+		wantSampled bool
+	}{
+		{
+			name:        "sampled",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantSC:      otel.SpanContext{TraceID: traceID, SpanID: spanID, TraceFlags: otel.FlagsSampled},
+			wantSampled: true,
+		},
+		{
+			name:        "unsampled",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			wantSC:      otel.SpanContext{TraceID: traceID, SpanID: spanID},
+		},
+		{
+			name:        "invalid",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("traceparent", tt.traceparent)
+
+			ctx, sampled := propagators.TraceContext{}.ExtractSampled(context.Background(), header)
+			if sampled != tt.wantSampled {
+				t.Errorf("got sampled %t, want %t", sampled, tt.wantSampled)
+			}
+			if diff := cmp.Diff(tt.wantSC, otel.RemoteSpanContextFromContext(ctx)); diff != "" {
+				t.Errorf("extracted span context differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("ignores the remote span context already in the context", func(t *testing.T) {
+		ctx := otel.ContextWithRemoteSpanContext(context.Background(), otel.SpanContext{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: otel.FlagsSampled,
+		})
+		if _, sampled := (propagators.TraceContext{}).ExtractSampled(ctx, http.Header{}); sampled {
+			t.Error("got sampled true without a traceparent header, want false")
+		}
+	})
+}
+
+func TestTraceContextInjectOmitsEmptyTracestate(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+	}{
+		{
+			name: "no tracestate",
+			header: http.Header{
+				"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			},
+		},
+		{
+			name: "blank tracestate",
+			header: http.Header{
+				"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+				"Tracestate":  []string{"  "},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, prop := range []propagators.TraceContext{{}, propagators.NewTraceContext(propagators.WithStrictOutput())} {
+				ctx := prop.Extract(context.Background(), tt.header)
+				injected := http.Header{}
+				prop.Inject(ctx, injected)
+				if _, ok := injected["Tracestate"]; ok {
+					t.Errorf("got tracestate header %q, want none", injected.Get("tracestate"))
+				}
+			}
+		})
+	}
 }
@@ -193,6 +193,16 @@ func (sc SpanContext) IsSampled() bool {
 	return sc.TraceFlags&FlagsSampled == FlagsSampled
 }
 
+// Equal returns if sc and other identify the same span with the same
+// sampled, deferred and debug bits. The unused bits of the trace flags
+// are ignored.
+func (sc SpanContext) Equal(other SpanContext) bool {
+	const flags = FlagsSampled | FlagsDeferred | FlagsDebug
+	return sc.TraceID == other.TraceID &&
+		sc.SpanID == other.SpanID &&
+		sc.TraceFlags&flags == other.TraceFlags&flags
+}
+
 type traceContextKeyType int
 
 const (
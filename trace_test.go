@@ -270,6 +270,81 @@ func TestSpanContextIsSampled(t *testing.T) {
 	}
 }
 
+func TestSpanContextEqual(t *testing.T) {
+	sc := SpanContext{
+		TraceID:    TraceID([16]byte{1}),
+		SpanID:     SpanID([8]byte{42}),
+		TraceFlags: FlagsSampled,
+	}
+	for _, testcase := range []struct {
+		name  string
+		other SpanContext
+		want  bool
+	}{
+		{
+			name:  "same",
+			other: sc,
+			want:  true,
+		}, {
+			name: "different trace ID",
+			other: SpanContext{
+				TraceID:    TraceID([16]byte{2}),
+				SpanID:     sc.SpanID,
+				TraceFlags: FlagsSampled,
+			},
+			want: false,
+		}, {
+			name: "different span ID",
+			other: SpanContext{
+				TraceID:    sc.TraceID,
+				SpanID:     SpanID([8]byte{43}),
+				TraceFlags: FlagsSampled,
+			},
+			want: false,
+		}, {
+			name: "not sampled",
+			other: SpanContext{
+				TraceID: sc.TraceID,
+				SpanID:  sc.SpanID,
+			},
+			want: false,
+		}, {
+			name: "debug bit set",
+			other: SpanContext{
+				TraceID:    sc.TraceID,
+				SpanID:     sc.SpanID,
+				TraceFlags: FlagsSampled | FlagsDebug,
+			},
+			want: false,
+		}, {
+			name: "deferred bit set",
+			other: SpanContext{
+				TraceID:    sc.TraceID,
+				SpanID:     sc.SpanID,
+				TraceFlags: FlagsSampled | FlagsDeferred,
+			},
+			want: false,
+		}, {
+			name: "unused bits are ignored",
+			other: SpanContext{
+				TraceID:    sc.TraceID,
+				SpanID:     sc.SpanID,
+				TraceFlags: FlagsSampled | 0x80,
+			},
+			want: true,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			if have := sc.Equal(testcase.other); have != testcase.want {
+				t.Errorf("Want: %v, but have: %v", testcase.want, have)
+			}
+			if have := testcase.other.Equal(sc); have != testcase.want {
+				t.Errorf("Want: %v, but have: %v (reversed)", testcase.want, have)
+			}
+		})
+	}
+}
+
 func TestStringTraceID(t *testing.T) {
 	for _, testcase := range []struct {
 		name string